@@ -1,25 +1,46 @@
 package ai
 
 import (
+	"context"
 	"fmt"
-	"math"
 	"time"
 
 	"github.com/kevin-chtw/tw_common/gamebase/mahjong"
+	"github.com/kevin-chtw/tw_mjsc_svr/metrics"
+	"github.com/kevin-chtw/tw_mjsc_svr/tracing"
 	"github.com/topfreegames/pitaya/v3/pkg/logger"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 var inst *RichAI
-var globalLearnable bool
+var featureVersion = FeatureV1
+var seatViewProbe func(uid string) *SeatView
 
-type RichAI struct{}
+// RichAI 通过 Python 服务决策，client 为空时使用全局客户端
+type RichAI struct {
+	client *HTTPAIClient
+}
+
+// SetSeatViewProbe 注册服务端状态查询函数（由游戏模块提供）
+func SetSeatViewProbe(probe func(uid string) *SeatView) {
+	seatViewProbe = probe
+}
+
+// ProbeSeatView 查询玩家在服务端的真实状态，未注册或找不到时返回nil
+func ProbeSeatView(uid string) *SeatView {
+	if seatViewProbe == nil {
+		return nil
+	}
+	return seatViewProbe(uid)
+}
 
-func SetTrainingMode(enable bool) {
-	globalLearnable = enable
+// SetFeatureVersion 设置观察向量版本（需与Python模型输入维度一致）
+func SetFeatureVersion(version int) {
+	featureVersion = version
 }
 
-func IsTrainingMode() bool {
-	return globalLearnable
+func GetFeatureVersion() int {
+	return featureVersion
 }
 
 func GetRichAI() *RichAI {
@@ -34,49 +55,44 @@ type Decision struct {
 	Tile    mahjong.Tile `json:"tile"`
 	QValue  float32      `json:"q_value"`
 	Obs     []float32    `json:"obs,omitempty"`
+	Shanten int          `json:"-"` // 决策时的向听数（用于奖励塑形）
 }
 
 // Step - 通过 HTTP 调用 Python AI 服务
-func (ai *RichAI) Step(state *GameState) *Decision {
+func (ai *RichAI) Step(ctx context.Context, state *GameState) *Decision {
 	start := time.Now()
+	ctx, span := tracing.Tracer().Start(ctx, "ai.step")
+	defer span.End()
 
 	// 生成观察向量
-	feat := state.ToRichFeature()
-	obs := feat.ToVector()
+	obs := state.Observe(featureVersion)
 
 	// 收集所有可行的动作
-	var candidates []*Decision
-	if state.Operates.HasOperate(mahjong.OperateDiscard) {
-		candidates = append(candidates, ai.addDiscards(state)...)
-	}
-	if state.Operates.HasOperate(mahjong.OperateHu) {
-		candidates = append(candidates, ai.hu(state))
-	}
-	if state.Operates.HasOperate(mahjong.OperatePon) {
-		candidates = append(candidates, ai.pon(state))
-	}
-	if state.Operates.HasOperate(mahjong.OperateKon) {
-		candidates = append(candidates, ai.addKons(state)...)
-	}
-	if state.Operates.HasOperate(mahjong.OperatePass) {
-		candidates = append(candidates, ai.pass(state))
-	}
-
+	candidates := ai.Candidates(state)
 	if len(candidates) == 0 {
 		logger.Log.Errorf("No valid candidates")
 		return nil
 	}
 
-	httpClient := GetHTTPAIClient()
+	httpClient := ai.client
+	if httpClient == nil {
+		httpClient = GetHTTPAIClient()
+	}
 	if httpClient == nil {
 		logger.Log.Errorf("HTTP AI client not initialized, using fallback")
+		metrics.AIFallbacks.WithLabelValues(metrics.FallbackNoClient).Inc()
+		span.SetAttributes(attribute.String("fallback", metrics.FallbackNoClient))
 		return candidates[0]
 	}
 
 	// 发送GameState和候选动作给Python
-	decision, err := httpClient.GetDecision(state, obs, candidates)
+	decisionStart := time.Now()
+	decision, err := httpClient.GetDecision(ctx, state, obs, candidates)
+	metrics.AIDecisionLatency.Observe(time.Since(decisionStart).Seconds())
 	if err != nil || decision == nil {
 		logger.Log.Warnf("GetDecision failed: %v, using fallback to first candidate", err)
+		metrics.AIFallbacks.WithLabelValues(metrics.FallbackError).Inc()
+		span.SetAttributes(attribute.String("fallback", metrics.FallbackError))
 		return candidates[0]
 	}
 
@@ -104,11 +120,13 @@ func (ai *RichAI) Step(state *GameState) *Decision {
 		}
 		logger.Log.Warnf("AI returned invalid decision (operate=%d, tile=%d), not in candidates: [%s], using first candidate",
 			decision.Operate, decision.Tile, candStr)
+		metrics.AIFallbacks.WithLabelValues(metrics.FallbackInvalid).Inc()
+		span.SetAttributes(attribute.String("fallback", metrics.FallbackInvalid))
 		return candidates[0]
 	}
 
 	// 记录决策用于训练
-	if globalLearnable && decision.Operate != int(mahjong.OperateNone) {
+	if state.Learnable && decision.Operate != int(mahjong.OperateNone) {
 		decision.Obs = obs
 		state.RecordDecision(decision.Operate, decision.Tile, obs)
 	}
@@ -122,6 +140,27 @@ func (ai *RichAI) Step(state *GameState) *Decision {
 	return decision
 }
 
+// Candidates 收集所有可行的动作
+func (ai *RichAI) Candidates(state *GameState) []*Decision {
+	var candidates []*Decision
+	if state.Operates.HasOperate(mahjong.OperateDiscard) {
+		candidates = append(candidates, ai.addDiscards(state)...)
+	}
+	if state.Operates.HasOperate(mahjong.OperateHu) {
+		candidates = append(candidates, ai.hu(state))
+	}
+	if state.Operates.HasOperate(mahjong.OperatePon) {
+		candidates = append(candidates, ai.pon(state))
+	}
+	if state.Operates.HasOperate(mahjong.OperateKon) {
+		candidates = append(candidates, ai.addKons(state)...)
+	}
+	if state.Operates.HasOperate(mahjong.OperatePass) {
+		candidates = append(candidates, ai.pass(state))
+	}
+	return candidates
+}
+
 func (ai *RichAI) addDiscards(state *GameState) []*Decision {
 	decisions := make([]*Decision, 0)
 	lackSuit := state.PlayerLacks[state.CurrentSeat]
@@ -209,7 +248,7 @@ func (ai *RichAI) pass(_ *GameState) *Decision {
 
 // QueueTraining - 游戏结束时发送训练数据到 Python
 func (ai *RichAI) QueueTraining(finalState *GameState) {
-	if !globalLearnable {
+	if !finalState.Learnable {
 		return
 	}
 
@@ -228,14 +267,12 @@ func (ai *RichAI) QueueTraining(finalState *GameState) {
 		}
 	}
 
-	shapedReward := finalScore
+	logger.Log.Warnf("QueueTraining: isHu=%v, multi=%d,  steps=%d, finalScore=%.2f",
+		isHu, huMulti, decisionSteps, finalScore)
 
-	logger.Log.Warnf("QueueTraining: isHu=%v, multi=%d,  steps=%d, shapedReward=%.2f",
-		isHu, huMulti, decisionSteps, shapedReward)
-
-	httpClient := GetHTTPAIClient()
-	if httpClient == nil {
-		logger.Log.Warnf("HTTP AI client not initialized, skipping training")
+	sink := getEpisodeSink()
+	if sink == nil {
+		logger.Log.Warnf("no episode sink configured, skipping training")
 		return
 	}
 
@@ -246,17 +283,13 @@ func (ai *RichAI) QueueTraining(finalState *GameState) {
 
 	steps := make([]StepTransition, 0, len(finalState.DecisionHistory))
 	validSteps := 0
-	// 使用折扣因子分配奖励：越接近最终结果的决策，奖励越大
-	// 例如：最后一步 100%，倒数第二步 80%，倒数第三步 64%...
-	discountFactor := float32(0.97)
+	rewards := rewardFunc.Rewards(finalState)
+	events := finalState.DecisionEvents()
 
 	totalReward := float32(0)
 	for i := 0; i < len(finalState.DecisionHistory); i++ {
 		rec := &finalState.DecisionHistory[i]
-
-		// 计算折扣后的奖励：越早的决策，折扣越多
-		stepsFromEnd := len(finalState.DecisionHistory) - 1 - i
-		reward := shapedReward * float32(math.Pow(float64(discountFactor), float64(stepsFromEnd)))
+		reward := rewards[i]
 
 		var nextState []float32
 		done := false
@@ -273,25 +306,45 @@ func (ai *RichAI) QueueTraining(finalState *GameState) {
 			Reward:    reward,
 			NextState: nextState,
 			Done:      done,
+			Events:    toEvents(events[i]),
 		})
 		validSteps++
 	}
 
 	// 只有当有有效步骤时才发送
 	if validSteps > 0 {
+		meta := finalState.Meta
+		meta.Seat = finalState.CurrentSeat
+		meta.ScoreBase = finalState.ScoreBase
+		meta.FeatureVersion = featureVersion
 		episode := &Episode{
 			Steps:        steps,
 			ShapedReward: totalReward,
 			IsHu:         isHu,
 			HuMulti:      huMulti,
+			Meta:         &meta,
+		}
+		if err := sink.WriteEpisode(episode); err != nil {
+			logger.Log.Warnf("QueueTraining: write episode failed: %v", err)
+			return
 		}
-		httpClient.ReportEpisode(episode)
 		logger.Log.Infof("QueueTraining: sent %d valid steps out of %d total", validSteps, len(finalState.DecisionHistory))
 	} else {
 		logger.Log.Warnf("QueueTraining: no valid steps to send")
 	}
 }
 
+func toEvents(scoreEvents []ScoreEvent) []Event {
+	if len(scoreEvents) == 0 {
+		return nil
+	}
+	events := make([]Event, 0, len(scoreEvents))
+	for _, ev := range scoreEvents {
+		events = append(events, Event{Reason: ev.Reason, Scores: ev.Scores})
+	}
+	return events
+}
+
 func (ai *RichAI) SaveWeights(path string) error {
 	return nil // Python manages weights
 }
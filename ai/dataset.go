@@ -0,0 +1,259 @@
+package ai
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/kevin-chtw/tw_mjsc_svr/metrics"
+	"github.com/topfreegames/pitaya/v3/pkg/logger"
+)
+
+const (
+	datasetMetaFile  = "meta.json"
+	datasetShardGlob = "episodes-*.jsonl.gz"
+)
+
+// EpisodeSink 轨迹输出接口（HTTP上报、本地文件等）
+type EpisodeSink interface {
+	WriteEpisode(episode *Episode) error
+	Close() error
+}
+
+// EpisodeMeta 单局轨迹的元数据
+type EpisodeMeta struct {
+	MatchID        int32 `json:"match_id"`
+	TableID        int32 `json:"table_id"`
+	Seat           int   `json:"seat"`
+	Seed           int64 `json:"seed,omitempty"`
+	ScoreBase      int64 `json:"score_base"`
+	FeatureVersion int   `json:"feature_version"`
+}
+
+// DatasetMeta 整个数据集的元数据（写入 meta.json）
+type DatasetMeta struct {
+	Name      string            `json:"name"`
+	Rules     map[string]int32  `json:"rules,omitempty"`
+	Seeds     []int64           `json:"seeds,omitempty"`
+	Seats     map[int]string    `json:"seats,omitempty"` // 座位->策略
+	Extra     map[string]string `json:"extra,omitempty"`
+	ShardSize int               `json:"shard_size"`
+}
+
+var episodeSink EpisodeSink
+
+// SetEpisodeSink 设置轨迹输出，未设置时上报到 Python 服务
+func SetEpisodeSink(sink EpisodeSink) {
+	episodeSink = sink
+}
+
+func getEpisodeSink() EpisodeSink {
+	if episodeSink != nil {
+		return episodeSink
+	}
+	if httpAIClient != nil {
+		return httpAIClient
+	}
+	return nil
+}
+
+// MultiSink 同时输出到多个 sink
+type MultiSink []EpisodeSink
+
+func (m MultiSink) WriteEpisode(episode *Episode) error {
+	var errs []error
+	for _, sink := range m {
+		if err := sink.WriteEpisode(episode); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m MultiSink) Close() error {
+	var errs []error
+	for _, sink := range m {
+		if err := sink.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// FileSink 本地数据集：按 shardSize 局切分为 gzip 压缩的 jsonl 文件
+type FileSink struct {
+	mu        sync.Mutex
+	dir       string
+	shardSize int
+	shard     int
+	count     int
+	file      *os.File
+	gz        *gzip.Writer
+	enc       *json.Encoder
+}
+
+// NewFileSink 创建本地数据集目录并写入元数据
+func NewFileSink(dir string, shardSize int, meta *DatasetMeta) (*FileSink, error) {
+	if shardSize <= 0 {
+		shardSize = 1000
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	if meta != nil {
+		meta.ShardSize = shardSize
+		data, err := json.MarshalIndent(meta, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(filepath.Join(dir, datasetMetaFile), data, 0o644); err != nil {
+			return nil, err
+		}
+	}
+	// 继续已有数据集时从下一个分片开始，避免覆盖
+	shards, err := filepath.Glob(filepath.Join(dir, datasetShardGlob))
+	if err != nil {
+		return nil, err
+	}
+	return &FileSink{dir: dir, shardSize: shardSize, shard: len(shards)}, nil
+}
+
+func (f *FileSink) WriteEpisode(episode *Episode) (err error) {
+	defer func() { metrics.Episode("file", err) }()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.enc == nil || f.count >= f.shardSize {
+		if err := f.rotate(); err != nil {
+			return err
+		}
+	}
+	if err := f.enc.Encode(episode); err != nil {
+		return err
+	}
+	f.count++
+	return nil
+}
+
+func (f *FileSink) rotate() error {
+	if err := f.closeShard(); err != nil {
+		return err
+	}
+	path := filepath.Join(f.dir, fmt.Sprintf("episodes-%05d.jsonl.gz", f.shard))
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	f.file = file
+	f.gz = gzip.NewWriter(file)
+	f.enc = json.NewEncoder(f.gz)
+	f.count = 0
+	f.shard++
+	logger.Log.Infof("dataset shard opened: %s", path)
+	return nil
+}
+
+func (f *FileSink) closeShard() error {
+	if f.file == nil {
+		return nil
+	}
+	err := errors.Join(f.gz.Close(), f.file.Close())
+	f.file, f.gz, f.enc = nil, nil, nil
+	return err
+}
+
+func (f *FileSink) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.closeShard()
+}
+
+// DatasetReader 顺序读取本地数据集中的所有轨迹
+type DatasetReader struct {
+	Meta   *DatasetMeta
+	shards []string
+	file   *os.File
+	gz     *gzip.Reader
+	dec    *json.Decoder
+}
+
+// OpenDataset 打开 FileSink 写出的数据集目录
+func OpenDataset(dir string) (*DatasetReader, error) {
+	shards, err := filepath.Glob(filepath.Join(dir, datasetShardGlob))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(shards)
+	r := &DatasetReader{shards: shards}
+
+	data, err := os.ReadFile(filepath.Join(dir, datasetMetaFile))
+	if err == nil {
+		r.Meta = &DatasetMeta{}
+		if err := json.Unmarshal(data, r.Meta); err != nil {
+			return nil, err
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Next 返回下一局轨迹，全部读完返回 io.EOF
+func (r *DatasetReader) Next() (*Episode, error) {
+	for {
+		if r.dec == nil {
+			if len(r.shards) == 0 {
+				return nil, io.EOF
+			}
+			if err := r.open(r.shards[0]); err != nil {
+				return nil, err
+			}
+			r.shards = r.shards[1:]
+		}
+		episode := &Episode{}
+		err := r.dec.Decode(episode)
+		if err == nil {
+			return episode, nil
+		}
+		if err != io.EOF {
+			return nil, err
+		}
+		if err := r.closeShard(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (r *DatasetReader) open(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	gz, err := gzip.NewReader(bufio.NewReader(file))
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("open shard %s: %w", path, err)
+	}
+	r.file, r.gz, r.dec = file, gz, json.NewDecoder(gz)
+	return nil
+}
+
+func (r *DatasetReader) closeShard() error {
+	if r.file == nil {
+		return nil
+	}
+	err := errors.Join(r.gz.Close(), r.file.Close())
+	r.file, r.gz, r.dec = nil, nil, nil
+	return err
+}
+
+func (r *DatasetReader) Close() error {
+	return r.closeShard()
+}
@@ -0,0 +1,121 @@
+package ai
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeEpisodes(t *testing.T, dir string, shardSize int, meta *DatasetMeta, from, n int) {
+	t.Helper()
+	sink, err := NewFileSink(dir, shardSize, meta)
+	if err != nil {
+		t.Fatalf("NewFileSink: %v", err)
+	}
+	for i := from; i < from+n; i++ {
+		episode := &Episode{HuMulti: int64(i), Meta: &EpisodeMeta{Seat: i % 4}}
+		if err := sink.WriteEpisode(episode); err != nil {
+			t.Fatalf("WriteEpisode(%d): %v", i, err)
+		}
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+}
+
+// shardLines 解压一个分片并统计 jsonl 行数
+func shardLines(t *testing.T, path string) int {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatalf("gzip %s: %v", path, err)
+	}
+	defer gz.Close()
+	lines := 0
+	scanner := bufio.NewScanner(gz)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		lines++
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	return lines
+}
+
+func TestFileSinkRotate(t *testing.T) {
+	dir := t.TempDir()
+	writeEpisodes(t, dir, 2, &DatasetMeta{Name: "test"}, 0, 5)
+
+	shards, err := filepath.Glob(filepath.Join(dir, datasetShardGlob))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []int{2, 2, 1}
+	if len(shards) != len(want) {
+		t.Fatalf("shards = %v, want %d", shards, len(want))
+	}
+	for i, path := range shards {
+		if got := shardLines(t, path); got != want[i] {
+			t.Errorf("%s: %d episodes, want %d", filepath.Base(path), got, want[i])
+		}
+	}
+}
+
+func TestFileSinkResume(t *testing.T) {
+	dir := t.TempDir()
+	writeEpisodes(t, dir, 2, nil, 0, 3)
+	first, err := os.ReadFile(filepath.Join(dir, "episodes-00000.jsonl.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 继续写入时从下一个分片开始，已有分片不变
+	writeEpisodes(t, dir, 2, nil, 3, 1)
+	again, err := os.ReadFile(filepath.Join(dir, "episodes-00000.jsonl.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(first) != string(again) {
+		t.Error("existing shard overwritten")
+	}
+	if got := shardLines(t, filepath.Join(dir, "episodes-00002.jsonl.gz")); got != 1 {
+		t.Errorf("resumed shard has %d episodes, want 1", got)
+	}
+}
+
+func TestDatasetRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	meta := &DatasetMeta{Name: "test", Seeds: []int64{7, 8}, Seats: map[int]string{0: StrategyAI}}
+	writeEpisodes(t, dir, 3, meta, 0, 4)
+	writeEpisodes(t, dir, 3, nil, 4, 3)
+
+	r, err := OpenDataset(dir)
+	if err != nil {
+		t.Fatalf("OpenDataset: %v", err)
+	}
+	defer r.Close()
+	if r.Meta == nil || r.Meta.Name != "test" || r.Meta.ShardSize != 3 || len(r.Meta.Seeds) != 2 || r.Meta.Seats[0] != StrategyAI {
+		t.Errorf("meta = %+v", r.Meta)
+	}
+	for i := range 7 {
+		episode, err := r.Next()
+		if err != nil {
+			t.Fatalf("Next(%d): %v", i, err)
+		}
+		if episode.HuMulti != int64(i) || episode.Meta == nil || episode.Meta.Seat != i%4 {
+			t.Errorf("episode %d = %+v", i, episode)
+		}
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("Next after last = %v, want io.EOF", err)
+	}
+}
@@ -9,6 +9,18 @@ const (
 	HistoryDim = HistorySteps * HistoryStepDim
 )
 
+const (
+	FeatureV1 = 1 // 基础特征：手牌+缺门+历史操作
+	FeatureV2 = 2 // 扩展特征：增加牌河、副露、可见牌、胡牌状态、分数、剩余牌墙
+)
+
+const (
+	// WallTiles 四川麻将总牌数（万条筒各36张）
+	WallTiles = 108
+	// ScoreNorm 分数归一化系数（以底分为单位）
+	ScoreNorm = 64.0
+)
+
 // RichFeature 精简特征（专注于核心信息）
 type RichFeature struct {
 	TotalTiles    float32             // 1 - 总牌张数（归一化）
@@ -36,3 +48,52 @@ func (f RichFeature) ToVector() []float32 {
 
 	return out
 }
+
+// RichFeatureV2 扩展特征（在V1基础上增加对手信息，用于学习防守）
+type RichFeatureV2 struct {
+	RichFeature
+	WallRemaining float32        // 1 - 牌墙剩余张数（除以108归一化）
+	Discards      [4][34]float32 // 4×34 - 各玩家牌河
+	PonMelds      [4][34]float32 // 4×34 - 各玩家碰牌
+	KonMelds      [4][34]float32 // 4×34 - 各玩家杠牌
+	Visible       [34]float32    // 34 - 每种牌已可见张数（除以4归一化）
+	Won           [4]float32     // 4 - 各玩家是否已胡牌
+	Scores        [4]float32     // 4 - 各玩家当前得分（以底分为单位归一化）
+}
+
+// FeatureDim 返回各特征版本的向量维度
+func FeatureDim(version int) int {
+	if version == FeatureV2 {
+		// 牌墙剩余张数替换了 V1 的总牌张数，基础部分仍为 56
+		return 56 + 34*4*3 + 34 + 4 + 4 + HistoryDim // 4806
+	}
+	return 56 + HistoryDim // 4356
+}
+
+// ToVector flatten → []float32 扩展特征向量（历史操作序列放在最后）
+func (f RichFeatureV2) ToVector() []float32 {
+	out := make([]float32, 0, FeatureDim(FeatureV2))
+	out = append(out, f.WallRemaining)     // 1
+	out = append(out, f.CurrentSeat[:]...) // 4
+	out = append(out, f.Operates[:]...)    // 5
+	out = append(out, f.Hand[:]...)        // 34
+	for i := range 4 {
+		out = append(out, f.PlayerLacks[i][:]...) // 4×3 = 12
+	}
+	for i := range 4 {
+		out = append(out, f.Discards[i][:]...) // 4×34 = 136
+	}
+	for i := range 4 {
+		out = append(out, f.PonMelds[i][:]...) // 4×34 = 136
+	}
+	for i := range 4 {
+		out = append(out, f.KonMelds[i][:]...) // 4×34 = 136
+	}
+	out = append(out, f.Visible[:]...) // 34
+	out = append(out, f.Won[:]...)     // 4
+	out = append(out, f.Scores[:]...)  // 4
+	// 历史操作序列 (4300)
+	out = append(out, f.ActionHistory[:]...)
+
+	return out
+}
@@ -0,0 +1,33 @@
+package ai
+
+import "testing"
+
+func TestFeatureDim(t *testing.T) {
+	tests := []struct {
+		name    string
+		version int
+		vector  []float32
+		want    int
+	}{
+		{"v1", FeatureV1, RichFeature{}.ToVector(), 4356},
+		{"v2", FeatureV2, RichFeatureV2{}.ToVector(), 4806},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FeatureDim(tt.version); got != tt.want {
+				t.Errorf("FeatureDim(%d) = %d, want %d", tt.version, got, tt.want)
+			}
+			if len(tt.vector) != FeatureDim(tt.version) {
+				t.Errorf("len(ToVector()) = %d, FeatureDim(%d) = %d", len(tt.vector), tt.version, FeatureDim(tt.version))
+			}
+		})
+	}
+}
+
+func TestObserveDim(t *testing.T) {
+	for _, version := range []int{FeatureV1, FeatureV2} {
+		if got := len(NewGameState().Observe(version)); got != FeatureDim(version) {
+			t.Errorf("len(Observe(%d)) = %d, want %d", version, got, FeatureDim(version))
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package ai
 
 import (
+	"slices"
+
 	"github.com/kevin-chtw/tw_common/gamebase/mahjong"
 	"github.com/kevin-chtw/tw_proto/game/pbmj"
 )
@@ -12,6 +14,14 @@ type ActionRecord struct {
 	TileIndex int // 牌索引
 }
 
+// ScoreEvent 一次分数变化（杠、胡、呼叫转移、退杠、查叫等）
+type ScoreEvent struct {
+	Step   int     // 发生时自己已做出的决策数，归属到第 Step-1 个决策
+	Reason int32   // 分数变化原因（mahjong.ScoreReasonXXX）
+	Scores []int64 // 各座位得分变化
+	Delta  int64   // 自己的得分变化
+}
+
 // GameState 小型状态快照
 type GameState struct {
 	Operates        *mahjong.Operates      // 可执行操作
@@ -28,13 +38,23 @@ type GameState struct {
 	DecisionHistory []Decision             // 决策历史记录（用于训练，不限制长度，不生成特征）
 	ActionHistory   []ActionRecord         // 实现操作历史记录（用于生成特征，限制60条）
 	CallData        map[int32]*pbmj.CallData
+	Discards        map[int][]mahjong.Tile // 牌河（玩家ID->打出且未被碰杠胡的牌）
+	LastDiscardSeat int                    // 最近出牌的玩家座位号
+	BuKonSeat       int                    // 刚补杠、杠牌可被抢的玩家座位号，-1 表示没有
+	Scores          [4]int64               // 各玩家当前累计得分
+	ScoreBase       int64                  // 底分
+	Meta            EpisodeMeta            // 轨迹元数据（跨局保留）
+	Learnable       bool                   // 是否记录决策用于训练（由所在桌的配置决定）
+	ScoreEvents     []ScoreEvent           // 分数变化记录（用于即时奖励计算）
+	DealIns         []int                  // 点炮的决策下标
+	DealInCount     int                    // 点炮次数
 	// 终局统计信息
 	FinalScore float32 // 最终得分（包含点炮惩罚）
 }
 
 func NewGameState() *GameState {
 	return &GameState{
-		TotalTiles:      136,
+		TotalTiles:      WallTiles,
 		Operates:        mahjong.NewOperates(int32(mahjong.OperateNone)),
 		Hand:            make(map[mahjong.Tile]int),
 		PonTiles:        make(map[int][]mahjong.Tile),
@@ -45,6 +65,11 @@ func NewGameState() *GameState {
 		DecisionHistory: []Decision{},
 		ActionHistory:   []ActionRecord{},
 		CallData:        make(map[int32]*pbmj.CallData),
+		Discards:        make(map[int][]mahjong.Tile),
+		LastDiscardSeat: -1,
+		BuKonSeat:       -1,
+		ScoreBase:       1,
+		PlayerLacks:     [4]mahjong.EColor{mahjong.ColorUndefined, mahjong.ColorUndefined, mahjong.ColorUndefined, mahjong.ColorUndefined},
 	}
 }
 
@@ -53,6 +78,7 @@ func (s *GameState) RecordDecision(operate int, tile mahjong.Tile, obs []float32
 		Operate: operate,
 		Tile:    tile,
 		Obs:     obs,
+		Shanten: s.SelfShanten(),
 	}
 
 	s.DecisionHistory = append(s.DecisionHistory, record)
@@ -72,6 +98,106 @@ func (s *GameState) RecordAction(seat int, operate int, tile mahjong.Tile) {
 	}
 }
 
+// IsWallEmpty 牌墙是否已摸完（流局）
+func (s *GameState) IsWallEmpty() bool {
+	return s.TotalTiles <= 0
+}
+
+// SelfShanten 自己当前手牌的向听数
+func (s *GameState) SelfShanten() int {
+	seat := s.CurrentSeat
+	melds := len(s.PonTiles[seat]) + len(s.KonTiles[seat])
+	return Shanten(s.Hand, melds, s.PlayerLacks[seat])
+}
+
+// FinalShanten 终局向听数，胡牌记为-1
+func (s *GameState) FinalShanten() int {
+	if slices.Contains(s.HuPlayers, s.CurrentSeat) {
+		return -1
+	}
+	return s.SelfShanten()
+}
+
+// RecordDiscard 记录出牌进入牌河
+func (s *GameState) RecordDiscard(seat int, tile mahjong.Tile) {
+	s.LastTile = tile
+	s.LastDiscardSeat = seat
+	s.BuKonSeat = -1
+	s.Discards[seat] = append(s.Discards[seat], tile)
+}
+
+// TakeLastDiscard 最近打出的牌被碰、直杠或点炮胡后从牌河中移除
+func (s *GameState) TakeLastDiscard(tile mahjong.Tile) {
+	seat := s.LastDiscardSeat
+	discards := s.Discards[seat]
+	if n := len(discards); n > 0 && discards[n-1] == tile {
+		s.Discards[seat] = discards[:n-1]
+	}
+	s.LastDiscardSeat = -1
+}
+
+// ApplyScoreChange 累加一次分数变化并记录得分事件
+func (s *GameState) ApplyScoreChange(reason int32, scores []int64) {
+	for i, v := range scores {
+		if i < len(s.Scores) {
+			s.Scores[i] += v
+		}
+	}
+	ev := ScoreEvent{
+		Step:   len(s.DecisionHistory),
+		Reason: reason,
+		Scores: scores,
+	}
+	if s.CurrentSeat >= 0 && s.CurrentSeat < len(scores) {
+		ev.Delta = scores[s.CurrentSeat]
+	}
+	s.ScoreEvents = append(s.ScoreEvents, ev)
+}
+
+// DecisionEvents 返回归属到每个决策的分数变化事件
+func (s *GameState) DecisionEvents() [][]ScoreEvent {
+	events := make([][]ScoreEvent, len(s.DecisionHistory))
+	if len(events) == 0 {
+		return events
+	}
+	for _, ev := range s.ScoreEvents {
+		idx := min(max(ev.Step-1, 0), len(events)-1)
+		events[idx] = append(events[idx], ev)
+	}
+	return events
+}
+
+// VisibleCount 统计自己可见的某张牌数量（手牌+牌河+副露）
+func (s *GameState) VisibleCount(tile mahjong.Tile) int {
+	count := s.Hand[tile]
+	for seat := range 4 {
+		for _, t := range s.Discards[seat] {
+			if t == tile {
+				count++
+			}
+		}
+		for _, t := range s.PonTiles[seat] {
+			if t == tile {
+				count += 3
+			}
+		}
+		for _, t := range s.KonTiles[seat] {
+			if t == tile {
+				count += 4
+			}
+		}
+	}
+	return min(count, 4)
+}
+
+// Observe 按特征版本生成观察向量
+func (s *GameState) Observe(version int) []float32 {
+	if version == FeatureV2 {
+		return s.ToRichFeatureV2().ToVector()
+	}
+	return s.ToRichFeature().ToVector()
+}
+
 func (s *GameState) ToRichFeature() *RichFeature {
 	r := &RichFeature{
 		Hand:          [34]float32{},
@@ -177,3 +303,58 @@ func (s *GameState) ToRichFeature() *RichFeature {
 
 	return r
 }
+
+func (s *GameState) ToRichFeatureV2() *RichFeatureV2 {
+	r := &RichFeatureV2{
+		RichFeature: *s.ToRichFeature(),
+	}
+
+	// 牌墙剩余张数（四川麻将共108张）
+	if s.TotalTiles > 0 {
+		r.WallRemaining = float32(s.TotalTiles) / WallTiles
+	}
+
+	visible := make(map[mahjong.Tile]bool)
+	for tile := range s.Hand {
+		visible[tile] = true
+	}
+	for seat := range 4 {
+		for _, tile := range s.Discards[seat] {
+			r.Discards[seat][mahjong.ToIndex(tile)]++
+			visible[tile] = true
+		}
+		for _, tile := range s.PonTiles[seat] {
+			r.PonMelds[seat][mahjong.ToIndex(tile)] = 1.0
+			visible[tile] = true
+		}
+		for _, tile := range s.KonTiles[seat] {
+			r.KonMelds[seat][mahjong.ToIndex(tile)] = 1.0
+			visible[tile] = true
+		}
+	}
+	for seat := range 4 {
+		for i := range r.Discards[seat] {
+			r.Discards[seat][i] /= 4.0
+		}
+	}
+
+	// 可见张数（归一化到0-1）
+	for tile := range visible {
+		r.Visible[mahjong.ToIndex(tile)] = float32(s.VisibleCount(tile)) / 4.0
+	}
+
+	// 胡牌状态
+	for _, seat := range s.HuPlayers {
+		if seat >= 0 && seat < 4 {
+			r.Won[seat] = 1.0
+		}
+	}
+
+	// 当前得分（以底分为单位）
+	scoreBase := max(s.ScoreBase, 1)
+	for seat := range 4 {
+		r.Scores[seat] = float32(s.Scores[seat]) / float32(scoreBase) / ScoreNorm
+	}
+
+	return r
+}
@@ -2,6 +2,7 @@ package ai
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -9,7 +10,12 @@ import (
 	"time"
 
 	"github.com/kevin-chtw/tw_common/gamebase/mahjong"
+	"github.com/kevin-chtw/tw_mjsc_svr/metrics"
+	"github.com/kevin-chtw/tw_mjsc_svr/tracing"
 	"github.com/topfreegames/pitaya/v3/pkg/logger"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // HTTPAIClient HTTP 客户端，负责与 Python AI 服务通信
@@ -20,9 +26,19 @@ type HTTPAIClient struct {
 
 var httpAIClient *HTTPAIClient
 
-// InitHTTPAIClient 初始化 HTTP AI 服务客户端
+// InitHTTPAIClient 初始化全局 HTTP AI 服务客户端
 func InitHTTPAIClient(addr string) error {
-	httpAIClient = &HTTPAIClient{
+	c, err := NewHTTPAIClient(addr)
+	if err != nil {
+		return err
+	}
+	httpAIClient = c
+	return nil
+}
+
+// NewHTTPAIClient 创建并检测 HTTP AI 服务客户端（评测时每个模型版本一个）
+func NewHTTPAIClient(addr string) (*HTTPAIClient, error) {
+	c := &HTTPAIClient{
 		baseURL: fmt.Sprintf("http://%s", addr),
 		client: &http.Client{
 			Timeout: 30 * time.Second, // 增加到30秒，避免训练时超时
@@ -30,18 +46,18 @@ func InitHTTPAIClient(addr string) error {
 	}
 
 	// 测试连接
-	resp, err := httpAIClient.client.Get(httpAIClient.baseURL + "/health")
+	resp, err := c.client.Get(c.baseURL + "/health")
 	if err != nil {
-		return fmt.Errorf("failed to connect to AI service: %v", err)
+		return nil, fmt.Errorf("failed to connect to AI service: %v", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("AI service not healthy: status %d", resp.StatusCode)
+		return nil, fmt.Errorf("AI service not healthy: status %d", resp.StatusCode)
 	}
 
 	logger.Log.Infof("✅ Connected to Python AI service at %s", addr)
-	return nil
+	return c, nil
 }
 
 // GetHTTPAIClient 获取全局 HTTP AI 客户端
@@ -57,6 +73,13 @@ type StepTransition struct {
 	Reward    float32   `json:"reward"`
 	NextState []float32 `json:"next_state,omitempty"`
 	Done      bool      `json:"done"`
+	Events    []Event   `json:"events,omitempty"` // 该决策之后发生的分数变化
+}
+
+// Event 分数变化事件
+type Event struct {
+	Reason int32   `json:"reason"`
+	Scores []int64 `json:"scores"`
 }
 
 // Episode 整局轨迹
@@ -66,6 +89,7 @@ type Episode struct {
 	IsHu         bool             `json:"is_hu"`
 	HuMulti      int64            `json:"hu_multi"`
 	IsLiuju      bool             `json:"is_liuju"`
+	Meta         *EpisodeMeta     `json:"meta,omitempty"`
 }
 
 // CandidateAction 候选动作
@@ -87,8 +111,16 @@ type GetDecisionResponse struct {
 }
 
 // GetDecision 发送观察向量和候选动作给Python，让AI决策
-func (c *HTTPAIClient) GetDecision(state *GameState, obs []float32, candidates []*Decision) (*Decision, error) {
+func (c *HTTPAIClient) GetDecision(ctx context.Context, state *GameState, obs []float32, candidates []*Decision) (d *Decision, err error) {
 	start := time.Now()
+	ctx, span := tracing.Tracer().Start(ctx, "ai.get_decision", trace.WithAttributes(attribute.Int("candidates", len(candidates))))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
 
 	// 转换candidates为可序列化格式
 	candActions := make([]CandidateAction, 0, len(candidates))
@@ -119,13 +151,20 @@ func (c *HTTPAIClient) GetDecision(state *GameState, obs []float32, candidates [
 
 	marshalTime := time.Since(start)
 
-	resp, err := c.client.Post(c.baseURL+"/get_decision", "application/json", bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/get_decision", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	tracing.Inject(ctx, req.Header)
+	resp, err := c.client.Do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	httpTime := time.Since(start) - marshalTime
+	span.SetAttributes(attribute.Int64("marshal_ms", marshalTime.Milliseconds()), attribute.Int64("http_ms", httpTime.Milliseconds()))
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
@@ -143,7 +182,7 @@ func (c *HTTPAIClient) GetDecision(state *GameState, obs []float32, candidates [
 		logger.Log.Warnf("GetDecision slow: total=%v, http=%v, marshal=%v", totalTime, httpTime, marshalTime)
 	}
 
-	d := &Decision{
+	d = &Decision{
 		Operate: respData.Operate,
 		Tile:    mahjong.FromIndex(respData.Tile),
 	}
@@ -166,17 +205,26 @@ func (c *HTTPAIClient) ReportEpisode(episode *Episode) {
 		resp, err := c.client.Post(c.baseURL+"/report_episode", "application/json", bytes.NewBuffer(jsonData))
 		if err != nil {
 			logger.Log.Warnf("Failed to report episode: %v", err)
+			metrics.Episode("http", err)
 			return
 		}
 		defer resp.Body.Close()
 
 		if resp.StatusCode != http.StatusOK {
 			body, _ := io.ReadAll(resp.Body)
+			err = fmt.Errorf("status %d", resp.StatusCode)
 			logger.Log.Warnf("AI service returned status %d: %s", resp.StatusCode, string(body))
 		}
+		metrics.Episode("http", err)
 	}()
 }
 
+// WriteEpisode 实现 EpisodeSink，异步上报不返回错误
+func (c *HTTPAIClient) WriteEpisode(episode *Episode) error {
+	c.ReportEpisode(episode)
+	return nil
+}
+
 // Close 关闭连接（HTTP client 不需要特殊关闭）
 func (c *HTTPAIClient) Close() error {
 	return nil
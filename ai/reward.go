@@ -0,0 +1,138 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+)
+
+const (
+	RewardTerminal    = "terminal"     // 终局得分按折扣分配到每一步
+	RewardScoreChange = "score_change" // 每次分数变化直接作为对应决策的即时奖励（默认）
+)
+
+// RewardFunc 为一局的每个决策计算奖励（返回长度与 DecisionHistory 相同）
+type RewardFunc interface {
+	Rewards(state *GameState) []float32
+}
+
+// RewardConfig 奖励配置，可从 json 文件加载
+type RewardConfig struct {
+	Scheme        string  `json:"scheme"`          // score_change（默认） | terminal
+	Gamma         float32 `json:"gamma"`           // 折扣因子
+	ShantenCoef   float32 `json:"shanten_coef"`    // 向听数塑形系数，0表示关闭
+	DealInPenalty float32 `json:"deal_in_penalty"` // 点炮额外惩罚（以底分为单位），0表示关闭
+}
+
+func DefaultRewardConfig() RewardConfig {
+	return RewardConfig{
+		Scheme: RewardScoreChange,
+		Gamma:  0.97,
+	}
+}
+
+// LoadRewardConfig 从 json 文件加载奖励配置，未填写的字段使用默认值
+func LoadRewardConfig(path string) (RewardConfig, error) {
+	cfg := DefaultRewardConfig()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("parse reward config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// NewRewardFunc 根据配置组合奖励函数
+func NewRewardFunc(cfg RewardConfig) (RewardFunc, error) {
+	var fn RewardFunc
+	switch cfg.Scheme {
+	case "", RewardScoreChange:
+		fn = &scoreChangeReward{}
+	case RewardTerminal:
+		fn = &terminalReward{gamma: cfg.Gamma}
+	default:
+		return nil, fmt.Errorf("unknown reward scheme: %s", cfg.Scheme)
+	}
+	if cfg.ShantenCoef != 0 {
+		fn = &shantenShaping{base: fn, coef: cfg.ShantenCoef, gamma: cfg.Gamma}
+	}
+	if cfg.DealInPenalty != 0 {
+		fn = &dealInPenalty{base: fn, penalty: cfg.DealInPenalty}
+	}
+	return fn, nil
+}
+
+var rewardFunc RewardFunc = &scoreChangeReward{}
+
+func SetRewardFunc(fn RewardFunc) {
+	rewardFunc = fn
+}
+
+// terminalReward 终局得分按折扣分配：越接近结束的决策奖励越大
+type terminalReward struct {
+	gamma float32
+}
+
+func (r *terminalReward) Rewards(state *GameState) []float32 {
+	n := len(state.DecisionHistory)
+	rewards := make([]float32, n)
+	for i := range n {
+		stepsFromEnd := n - 1 - i
+		rewards[i] = state.FinalScore * float32(math.Pow(float64(r.gamma), float64(stepsFromEnd)))
+	}
+	return rewards
+}
+
+// scoreChangeReward 每次分数变化归属到其之前的最后一个决策（开局前的变化归属到第一个决策）
+type scoreChangeReward struct{}
+
+func (r *scoreChangeReward) Rewards(state *GameState) []float32 {
+	rewards := make([]float32, len(state.DecisionHistory))
+	scoreBase := float32(max(state.ScoreBase, 1))
+	for i, events := range state.DecisionEvents() {
+		for _, ev := range events {
+			rewards[i] += float32(ev.Delta) / scoreBase
+		}
+	}
+	return rewards
+}
+
+// shantenShaping 基于势函数的向听数塑形：F = γ·Φ(s') - Φ(s)，Φ = -向听数
+type shantenShaping struct {
+	base  RewardFunc
+	coef  float32
+	gamma float32
+}
+
+func (r *shantenShaping) Rewards(state *GameState) []float32 {
+	rewards := r.base.Rewards(state)
+	n := len(state.DecisionHistory)
+	for i := range n {
+		next := state.FinalShanten()
+		if i+1 < n {
+			next = state.DecisionHistory[i+1].Shanten
+		}
+		cur := state.DecisionHistory[i].Shanten
+		rewards[i] += r.coef * (float32(cur) - r.gamma*float32(next))
+	}
+	return rewards
+}
+
+// dealInPenalty 点炮的出牌决策额外扣分
+type dealInPenalty struct {
+	base    RewardFunc
+	penalty float32
+}
+
+func (r *dealInPenalty) Rewards(state *GameState) []float32 {
+	rewards := r.base.Rewards(state)
+	for _, idx := range state.DealIns {
+		if idx >= 0 && idx < len(rewards) {
+			rewards[idx] -= r.penalty
+		}
+	}
+	return rewards
+}
@@ -0,0 +1,104 @@
+package ai
+
+import "github.com/kevin-chtw/tw_common/gamebase/mahjong"
+
+// Shanten 计算向听数（-1 表示已胡，0 表示听牌）
+// melds 为已有副露组数，缺门花色的牌不参与组合（必须打出）
+func Shanten(hand map[mahjong.Tile]int, melds int, lack mahjong.EColor) int {
+	var counts [27]int
+	for tile, count := range hand {
+		if count <= 0 || !tile.IsSuit() || tile.Color() == lack {
+			continue
+		}
+		idx := int(tile.Color()-mahjong.ColorCharacter)*9 + tile.Point()
+		if idx >= 0 && idx < len(counts) {
+			counts[idx] += count
+		}
+	}
+
+	best := normalShanten(&counts, melds)
+	if melds == 0 {
+		best = min(best, qiDuiShanten(&counts))
+	}
+	return best
+}
+
+// qiDuiShanten 七对向听数（四张相同算两对，对应龙七对）
+func qiDuiShanten(counts *[27]int) int {
+	pairs := 0
+	for _, c := range counts {
+		pairs += c / 2
+	}
+	return 6 - min(pairs, 7)
+}
+
+func normalShanten(counts *[27]int, melds int) int {
+	best := 8
+	var search func(i, mentsu, taatsu, pair int)
+	search = func(i, mentsu, taatsu, pair int) {
+		for i < len(counts) && counts[i] == 0 {
+			i++
+		}
+		if i >= len(counts) {
+			m := mentsu + melds
+			t := min(taatsu, 4-m)
+			if s := 8 - 2*m - t - pair; s < best {
+				best = s
+			}
+			return
+		}
+		point := i % 9
+		// 刻子
+		if counts[i] >= 3 {
+			counts[i] -= 3
+			search(i, mentsu+1, taatsu, pair)
+			counts[i] += 3
+		}
+		// 顺子
+		if point <= 6 && counts[i+1] > 0 && counts[i+2] > 0 {
+			counts[i]--
+			counts[i+1]--
+			counts[i+2]--
+			search(i, mentsu+1, taatsu, pair)
+			counts[i]++
+			counts[i+1]++
+			counts[i+2]++
+		}
+		if counts[i] >= 2 {
+			counts[i] -= 2
+			// 将
+			if pair == 0 {
+				search(i, mentsu, taatsu, 1)
+			}
+			// 对子搭子
+			if mentsu+melds+taatsu < 4 {
+				search(i, mentsu, taatsu+1, pair)
+			}
+			counts[i] += 2
+		}
+		if mentsu+melds+taatsu < 4 {
+			// 两面/边张搭子
+			if point <= 7 && counts[i+1] > 0 {
+				counts[i]--
+				counts[i+1]--
+				search(i, mentsu, taatsu+1, pair)
+				counts[i]++
+				counts[i+1]++
+			}
+			// 坎张搭子
+			if point <= 6 && counts[i+2] > 0 {
+				counts[i]--
+				counts[i+2]--
+				search(i, mentsu, taatsu+1, pair)
+				counts[i]++
+				counts[i+2]++
+			}
+		}
+		// 孤张
+		counts[i]--
+		search(i, mentsu, taatsu, pair)
+		counts[i]++
+	}
+	search(0, 0, 0, 0)
+	return best
+}
@@ -0,0 +1,106 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+	"sync"
+
+	"github.com/kevin-chtw/tw_common/gamebase/mahjong"
+)
+
+const (
+	StrategyAI   = "ai"   // Python 模型决策，"ai@host:port" 指定其他模型服务
+	StrategyRule = "rule" // 基于向听数的规则决策
+)
+
+var (
+	clientsMu sync.Mutex
+	clients   = make(map[string]*HTTPAIClient) // 地址 -> 客户端
+)
+
+// Strategy 机器人决策策略
+type Strategy interface {
+	Step(ctx context.Context, state *GameState) *Decision
+}
+
+// NewStrategy 按名称创建策略
+func NewStrategy(name string) (Strategy, error) {
+	if kind, addr, ok := strings.Cut(name, "@"); ok && kind == StrategyAI {
+		client, err := getClient(addr)
+		if err != nil {
+			return nil, err
+		}
+		return &RichAI{client: client}, nil
+	}
+	switch name {
+	case "", StrategyAI:
+		return GetRichAI(), nil
+	case StrategyRule:
+		return &RuleAI{}, nil
+	default:
+		return nil, fmt.Errorf("unknown strategy: %s", name)
+	}
+}
+
+func getClient(addr string) (*HTTPAIClient, error) {
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
+	if c, ok := clients[addr]; ok {
+		return c, nil
+	}
+	c, err := NewHTTPAIClient(addr)
+	if err != nil {
+		return nil, err
+	}
+	clients[addr] = c
+	return c, nil
+}
+
+// RuleAI 规则策略：能胡就胡，碰杠不增加向听数才碰杠，出牌选择出后向听数最小的牌
+type RuleAI struct{}
+
+func (r *RuleAI) Step(_ context.Context, state *GameState) *Decision {
+	candidates := GetRichAI().Candidates(state)
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	seat := state.CurrentSeat
+	melds := len(state.PonTiles[seat]) + len(state.KonTiles[seat])
+	lack := state.PlayerLacks[seat]
+	cur := Shanten(state.Hand, melds, lack)
+
+	var best *Decision
+	bestShanten := 99
+	for _, cand := range candidates {
+		tile := cand.Tile
+		switch cand.Operate {
+		case mahjong.OperateHu:
+			return cand
+		case mahjong.OperateDiscard:
+			hand := maps.Clone(state.Hand)
+			hand[tile]--
+			if s := Shanten(hand, melds, lack); s < bestShanten {
+				best, bestShanten = cand, s
+			}
+		case mahjong.OperatePon:
+			hand := maps.Clone(state.Hand)
+			hand[tile] -= 2
+			if Shanten(hand, melds+1, lack) <= cur && best == nil {
+				best = cand
+			}
+		case mahjong.OperateKon:
+			hand := maps.Clone(state.Hand)
+			delete(hand, tile)
+			if Shanten(hand, melds+1, lack) <= cur && best == nil {
+				best = cand
+			}
+		}
+	}
+	if best != nil {
+		return best
+	}
+	return candidates[len(candidates)-1]
+}
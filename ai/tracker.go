@@ -0,0 +1,235 @@
+package ai
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/kevin-chtw/tw_common/gamebase/mahjong"
+	"github.com/kevin-chtw/tw_proto/game/pbmj"
+	"github.com/kevin-chtw/tw_proto/game/pbsc"
+	"google.golang.org/protobuf/proto"
+)
+
+// Apply 根据服务端下发的 ack 更新状态（纯函数式 reducer，不产生网络请求）
+func (s *GameState) Apply(msg proto.Message) {
+	switch ack := msg.(type) {
+	case *pbmj.MJGameStartAck:
+		s.reset()
+	case *pbmj.MJOpenDoorAck:
+		s.applyOpenDoor(ack)
+	case *pbsc.SCSwapFinishAck:
+		if ack.Seat == int32(s.CurrentSeat) {
+			for _, tile := range ack.GetTiles() {
+				s.removeHand(mahjong.Tile(tile), 1)
+			}
+		}
+	case *pbsc.SCSwapTilesResultAck:
+		for _, st := range ack.GetSwapTiles() {
+			if st.To == int32(s.CurrentSeat) {
+				for _, tile := range st.Tiles {
+					s.Hand[mahjong.Tile(tile)]++
+				}
+			}
+		}
+	case *pbsc.SCDingQueResultAck:
+		for i, c := range ack.GetColors() {
+			if i < len(s.PlayerLacks) {
+				s.PlayerLacks[i] = mahjong.EColor(c)
+			}
+		}
+	case *pbmj.MJRequestAck:
+		if ack.Seat == int32(s.CurrentSeat) {
+			s.Operates = mahjong.NewOperates(ack.RequestType)
+		}
+	case *pbmj.MJDiscardAck:
+		s.applyDiscard(ack)
+	case *pbmj.MJDrawAck:
+		s.applyDraw(ack)
+	case *pbmj.MJPonAck:
+		s.applyPon(ack)
+	case *pbmj.MJKonAck:
+		s.applyKon(ack)
+	case *pbmj.MJHuAck:
+		s.applyHu(ack)
+	case *pbmj.MJScoreChangeAck:
+		s.ApplyScoreChange(ack.GetReason(), ack.GetScores())
+	case *pbmj.MJResultAck:
+		for _, player := range ack.PlayerResults {
+			if player.Seat == int32(s.CurrentSeat) {
+				s.FinalScore = float32(player.WinScore) / float32(max(s.ScoreBase, 1))
+			}
+		}
+	}
+}
+
+// reset 新一局开始，保留座位号和底分
+func (s *GameState) reset() {
+	seat, scoreBase, meta, learnable := s.CurrentSeat, s.ScoreBase, s.Meta, s.Learnable
+	*s = *NewGameState()
+	s.CurrentSeat = seat
+	s.ScoreBase = scoreBase
+	s.Meta = meta
+	s.Learnable = learnable
+}
+
+func (s *GameState) applyOpenDoor(ack *pbmj.MJOpenDoorAck) {
+	if ack.Seat != int32(s.CurrentSeat) {
+		return
+	}
+	for _, tile := range ack.GetTiles() {
+		s.Hand[mahjong.Tile(tile)]++
+	}
+	s.TotalTiles -= 13*4 + 1
+}
+
+func (s *GameState) applyDiscard(ack *pbmj.MJDiscardAck) {
+	tile := mahjong.Tile(ack.Tile)
+	s.RecordDiscard(int(ack.Seat), tile)
+	if ack.Seat == int32(s.CurrentSeat) {
+		s.removeHand(tile, 1)
+	}
+	s.RecordAction(int(ack.Seat), mahjong.OperateDiscard, tile)
+}
+
+// applyDraw 摸牌：LastTile 统一表示当前待决策的牌，他人摸牌时不可见
+func (s *GameState) applyDraw(ack *pbmj.MJDrawAck) {
+	s.TotalTiles--
+	s.LastDiscardSeat = -1
+	s.BuKonSeat = -1
+	if ack.Seat != int32(s.CurrentSeat) {
+		s.LastTile = mahjong.TileNull
+		return
+	}
+	tile := mahjong.Tile(ack.Tile)
+	s.LastTile = tile
+	s.Hand[tile]++
+	s.CallData = ack.CallData
+}
+
+func (s *GameState) applyPon(ack *pbmj.MJPonAck) {
+	seat := int(ack.Seat)
+	tile := mahjong.Tile(ack.Tile)
+	s.TakeLastDiscard(tile)
+	s.PonTiles[seat] = append(s.PonTiles[seat], tile)
+	if seat == s.CurrentSeat {
+		s.removeHand(tile, 2)
+		s.CallData = ack.CallData
+	}
+	s.RecordAction(seat, mahjong.OperatePon, tile)
+}
+
+// applyKon 根据已有碰牌和牌河推断杠类型：补杠(手牌-1)、直杠(手牌-3)、暗杠(手牌-4)
+func (s *GameState) applyKon(ack *pbmj.MJKonAck) {
+	seat := int(ack.Seat)
+	tile := mahjong.Tile(ack.Tile)
+	used := 4
+	s.BuKonSeat = -1
+	if idx := slices.Index(s.PonTiles[seat], tile); idx >= 0 {
+		s.PonTiles[seat] = slices.Delete(s.PonTiles[seat], idx, idx+1)
+		used = 1
+		s.BuKonSeat = seat
+	} else if s.LastDiscardSeat >= 0 && s.LastDiscardSeat != seat && s.LastTile == tile {
+		s.TakeLastDiscard(tile)
+		used = 3
+	}
+	s.KonTiles[seat] = append(s.KonTiles[seat], tile)
+	if seat == s.CurrentSeat {
+		s.removeHand(tile, used)
+	}
+	// 补杠时其他玩家可能抢杠，待决策的牌为杠牌
+	s.LastTile = tile
+	s.RecordAction(seat, mahjong.OperateKon, tile)
+}
+
+func (s *GameState) applyHu(ack *pbmj.MJHuAck) {
+	tile := mahjong.Tile(ack.Tile)
+	if s.BuKonSeat >= 0 && ack.PaoSeat == int32(s.BuKonSeat) {
+		s.robKon(s.BuKonSeat, tile)
+	}
+	s.TakeLastDiscard(tile)
+	if ack.PaoSeat == int32(s.CurrentSeat) {
+		s.DealInCount++
+		if len(s.DecisionHistory) > 0 {
+			s.DealIns = append(s.DealIns, len(s.DecisionHistory)-1)
+		}
+	}
+	for _, h := range ack.HuData {
+		s.HuPlayers = append(s.HuPlayers, int(h.Seat))
+		s.HuMultis[int(h.Seat)] = h.Multi
+		s.RecordAction(int(h.Seat), mahjong.OperateHu, tile)
+	}
+}
+
+// robKon 抢杠胡：被抢的补杠退回碰牌，杠牌归胡牌者
+func (s *GameState) robKon(seat int, tile mahjong.Tile) {
+	s.BuKonSeat = -1
+	idx := slices.Index(s.KonTiles[seat], tile)
+	if idx < 0 {
+		return
+	}
+	s.KonTiles[seat] = slices.Delete(s.KonTiles[seat], idx, idx+1)
+	s.PonTiles[seat] = append(s.PonTiles[seat], tile)
+}
+
+func (s *GameState) removeHand(tile mahjong.Tile, count int) {
+	s.Hand[tile] -= count
+	if s.Hand[tile] <= 0 {
+		delete(s.Hand, tile)
+	}
+}
+
+// SeatView 服务端真实状态（用于校验机器人视角的状态是否漂移）
+type SeatView struct {
+	Hand          []mahjong.Tile         // 自己的手牌
+	PonTiles      map[int][]mahjong.Tile // 各玩家碰牌
+	KonTiles      map[int][]mahjong.Tile // 各玩家杠牌
+	WallRemaining int                    // 牌墙剩余张数
+}
+
+// Diff 比较机器人视角与服务端状态，返回所有不一致项
+func (s *GameState) Diff(view *SeatView) []string {
+	diffs := make([]string, 0)
+	hand := make(map[mahjong.Tile]int)
+	for _, tile := range view.Hand {
+		hand[tile]++
+	}
+	for tile, count := range s.Hand {
+		if count != 0 && hand[tile] != count {
+			diffs = append(diffs, fmt.Sprintf("hand tile %d: bot=%d server=%d", tile, count, hand[tile]))
+		}
+	}
+	for tile, count := range hand {
+		if s.Hand[tile] == 0 {
+			diffs = append(diffs, fmt.Sprintf("hand tile %d: bot=0 server=%d", tile, count))
+		}
+	}
+	for seat := range 4 {
+		if !sameTiles(s.PonTiles[seat], view.PonTiles[seat]) {
+			diffs = append(diffs, fmt.Sprintf("seat %d pon: bot=%v server=%v", seat, s.PonTiles[seat], view.PonTiles[seat]))
+		}
+		if !sameTiles(s.KonTiles[seat], view.KonTiles[seat]) {
+			diffs = append(diffs, fmt.Sprintf("seat %d kon: bot=%v server=%v", seat, s.KonTiles[seat], view.KonTiles[seat]))
+		}
+	}
+	if s.TotalTiles != view.WallRemaining {
+		diffs = append(diffs, fmt.Sprintf("wall: bot=%d server=%d", s.TotalTiles, view.WallRemaining))
+	}
+	return diffs
+}
+
+func sameTiles(a, b []mahjong.Tile) bool {
+	count := make(map[mahjong.Tile]int)
+	for _, t := range a {
+		count[t]++
+	}
+	for _, t := range b {
+		count[t]--
+	}
+	for v := range maps.Values(count) {
+		if v != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,186 @@
+package ai
+
+import (
+	"testing"
+
+	"github.com/kevin-chtw/tw_common/gamebase/mahjong"
+	"github.com/kevin-chtw/tw_proto/game/pbmj"
+)
+
+var (
+	wan1 = mahjong.MakeTile(mahjong.ColorCharacter, 0)
+	wan2 = mahjong.MakeTile(mahjong.ColorCharacter, 1)
+	tong = mahjong.MakeTile(mahjong.ColorDot, 4)
+)
+
+// newTracked 座位0，手牌为 hand 的初始状态
+func newTracked(hand ...mahjong.Tile) *GameState {
+	s := NewGameState()
+	s.CurrentSeat = 0
+	tiles := make([]int32, 0, len(hand))
+	for _, tile := range hand {
+		tiles = append(tiles, int32(tile))
+	}
+	s.Apply(&pbmj.MJOpenDoorAck{Seat: 0, Tiles: tiles})
+	return s
+}
+
+func TestApplyOpenDoor(t *testing.T) {
+	s := newTracked(wan1, wan1, tong)
+	s.Apply(&pbmj.MJOpenDoorAck{Seat: 1, Tiles: []int32{int32(wan2)}})
+	if s.Hand[wan1] != 2 || s.Hand[tong] != 1 || s.Hand[wan2] != 0 {
+		t.Errorf("hand = %v", s.Hand)
+	}
+	if want := WallTiles - 13*4 - 1; s.TotalTiles != want {
+		t.Errorf("TotalTiles = %d, want %d", s.TotalTiles, want)
+	}
+}
+
+func TestApplyDrawDiscard(t *testing.T) {
+	s := newTracked(wan1)
+	wall := s.TotalTiles
+
+	s.Apply(&pbmj.MJDrawAck{Seat: 0, Tile: int32(wan2)})
+	if s.Hand[wan2] != 1 || s.LastTile != wan2 || s.TotalTiles != wall-1 {
+		t.Fatalf("self draw: hand=%v last=%d wall=%d", s.Hand, s.LastTile, s.TotalTiles)
+	}
+	s.Apply(&pbmj.MJDiscardAck{Seat: 0, Tile: int32(wan2)})
+	if _, ok := s.Hand[wan2]; ok {
+		t.Errorf("discarded tile still in hand: %v", s.Hand)
+	}
+	if len(s.Discards[0]) != 1 || s.LastDiscardSeat != 0 {
+		t.Errorf("discards = %v, last seat = %d", s.Discards, s.LastDiscardSeat)
+	}
+
+	s.Apply(&pbmj.MJDrawAck{Seat: 1, Tile: int32(tong)})
+	if s.LastTile != mahjong.TileNull || s.LastDiscardSeat != -1 || s.Hand[tong] != 0 {
+		t.Errorf("other draw leaked: last=%d seat=%d hand=%v", s.LastTile, s.LastDiscardSeat, s.Hand)
+	}
+}
+
+func TestApplyPon(t *testing.T) {
+	s := newTracked(wan1, wan1, tong)
+	s.Apply(&pbmj.MJDiscardAck{Seat: 2, Tile: int32(wan1)})
+	s.Apply(&pbmj.MJPonAck{Seat: 0, Tile: int32(wan1)})
+	if s.Hand[wan1] != 0 || s.Hand[tong] != 1 {
+		t.Errorf("hand = %v", s.Hand)
+	}
+	if len(s.PonTiles[0]) != 1 || len(s.Discards[2]) != 0 {
+		t.Errorf("pon = %v, discards = %v", s.PonTiles, s.Discards)
+	}
+}
+
+func TestApplyKon(t *testing.T) {
+	tests := []struct {
+		name  string
+		hand  []mahjong.Tile
+		setup func(s *GameState)
+		left  int
+	}{
+		{"an kon", []mahjong.Tile{wan1, wan1, wan1, wan1, tong}, func(*GameState) {}, 0},
+		{"zhi kon", []mahjong.Tile{wan1, wan1, wan1, tong}, func(s *GameState) {
+			s.Apply(&pbmj.MJDiscardAck{Seat: 1, Tile: int32(wan1)})
+		}, 0},
+		{"bu kon", []mahjong.Tile{wan1, tong}, func(s *GameState) {
+			s.PonTiles[0] = []mahjong.Tile{wan1}
+		}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTracked(tt.hand...)
+			tt.setup(s)
+			s.Apply(&pbmj.MJKonAck{Seat: 0, Tile: int32(wan1)})
+			if s.Hand[wan1] != tt.left || s.Hand[tong] != 1 {
+				t.Errorf("hand = %v", s.Hand)
+			}
+			if len(s.KonTiles[0]) != 1 || len(s.PonTiles[0]) != 0 {
+				t.Errorf("kon = %v, pon = %v", s.KonTiles, s.PonTiles)
+			}
+			if len(s.Discards[1]) != 0 {
+				t.Errorf("discards = %v", s.Discards)
+			}
+		})
+	}
+}
+
+func TestApplyRobKon(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(s *GameState)
+		hu    *pbmj.MJHuAck
+		pon   []mahjong.Tile // 补杠玩家（座位1）最终的碰牌
+		kon   []mahjong.Tile
+	}{
+		{"robbed", func(*GameState) {},
+			&pbmj.MJHuAck{Tile: int32(wan1), PaoSeat: 1, HuData: []*pbmj.MJHuData{{Seat: 0}}},
+			[]mahjong.Tile{wan1}, nil},
+		{"zimo after draw", func(s *GameState) {
+			s.Apply(&pbmj.MJDrawAck{Seat: 2})
+		}, &pbmj.MJHuAck{Tile: int32(wan2), PaoSeat: mahjong.SeatNull, HuData: []*pbmj.MJHuData{{Seat: 2}}},
+			nil, []mahjong.Tile{wan1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTracked(tong)
+			s.PonTiles[1] = []mahjong.Tile{wan1}
+			s.Apply(&pbmj.MJKonAck{Seat: 1, Tile: int32(wan1)})
+			tt.setup(s)
+			s.Apply(tt.hu)
+			view := &SeatView{
+				Hand:          []mahjong.Tile{tong},
+				PonTiles:      map[int][]mahjong.Tile{1: tt.pon},
+				KonTiles:      map[int][]mahjong.Tile{1: tt.kon},
+				WallRemaining: s.TotalTiles,
+			}
+			if diffs := s.Diff(view); len(diffs) != 0 {
+				t.Errorf("diffs = %v", diffs)
+			}
+		})
+	}
+}
+
+func TestApplyGameStartKeepsSeat(t *testing.T) {
+	s := newTracked(wan1)
+	s.CurrentSeat = 3
+	s.ScoreBase = 5
+	s.Apply(&pbmj.MJGameStartAck{})
+	if s.CurrentSeat != 3 || s.ScoreBase != 5 || len(s.Hand) != 0 || s.TotalTiles != WallTiles {
+		t.Errorf("reset: seat=%d base=%d hand=%v wall=%d", s.CurrentSeat, s.ScoreBase, s.Hand, s.TotalTiles)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	s := newTracked(wan1, wan1, tong)
+	s.PonTiles[2] = []mahjong.Tile{wan2}
+	view := func() *SeatView {
+		return &SeatView{
+			Hand:          []mahjong.Tile{tong, wan1, wan1},
+			PonTiles:      map[int][]mahjong.Tile{2: {wan2}},
+			KonTiles:      map[int][]mahjong.Tile{},
+			WallRemaining: s.TotalTiles,
+		}
+	}
+	if diffs := s.Diff(view()); len(diffs) != 0 {
+		t.Fatalf("same state diffs = %v", diffs)
+	}
+
+	tests := []struct {
+		name   string
+		change func(v *SeatView)
+	}{
+		{"hand count", func(v *SeatView) { v.Hand = v.Hand[:2] }},
+		{"hand extra", func(v *SeatView) { v.Hand = append(v.Hand, wan2) }},
+		{"pon", func(v *SeatView) { v.PonTiles[2] = nil }},
+		{"kon", func(v *SeatView) { v.KonTiles[1] = []mahjong.Tile{tong} }},
+		{"wall", func(v *SeatView) { v.WallRemaining-- }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := view()
+			tt.change(v)
+			if diffs := s.Diff(v); len(diffs) != 1 {
+				t.Errorf("diffs = %v, want exactly one", diffs)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/kevin-chtw/tw_mjsc_svr/ai"
+)
+
+// Config 评测运行配置
+type Config struct {
+	Strategies   []string `json:"strategies"`     // 参赛策略（4个，可用 ai@host:port 指定不同模型）
+	Tables       int      `json:"tables"`         // 桌数
+	Deals        int32    `json:"deals"`          // 每桌牌墙数
+	Rotations    int32    `json:"rotations"`      // 每副牌墙轮换座位次数
+	Seed         int64    `json:"seed"`           // 随机种子，第i桌使用 seed+i
+	AIAddr       string   `json:"ai_addr"`        // 默认 Python AI 服务地址
+	OutputDir    string   `json:"output_dir"`     // 报告输出目录
+	StartDelayMs int      `json:"start_delay_ms"` // 启动后等待多久开桌
+}
+
+func parseConfig(args []string) (*Config, error) {
+	cfg := &Config{}
+	fs := flag.NewFlagSet("arena", flag.ContinueOnError)
+	strategies := fs.String("strategies", "ai,ai,rule,rule", "strategies to compare, e.g. ai@host:50051,ai,rule,rule")
+	fs.IntVar(&cfg.Tables, "tables", 4, "number of tables")
+	deals := fs.Int("deals", 250, "walls per table")
+	rotations := fs.Int("rotations", playerCount, "seat rotations per wall")
+	fs.Int64Var(&cfg.Seed, "seed", 1, "base wall seed")
+	fs.StringVar(&cfg.AIAddr, "ai", "localhost:50051", "default python AI service address")
+	fs.StringVar(&cfg.OutputDir, "out", "arena", "report output directory")
+	fs.IntVar(&cfg.StartDelayMs, "delay-ms", 1000, "delay before creating tables")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	cfg.Strategies = strings.Split(*strategies, ",")
+	cfg.Deals = int32(*deals)
+	cfg.Rotations = int32(*rotations)
+	return cfg, cfg.validate()
+}
+
+func (c *Config) validate() error {
+	if c.Tables <= 0 || c.Deals <= 0 {
+		return fmt.Errorf("tables and deals must be positive")
+	}
+	if c.Rotations <= 0 || c.Rotations > playerCount {
+		return fmt.Errorf("rotations must be in [1, %d]", playerCount)
+	}
+	if c.Seed == 0 {
+		return fmt.Errorf("seed must be non-zero to duplicate walls")
+	}
+	// 种子以 int32 规则值下发到牌桌
+	if c.Seed < 0 || c.Seed+int64(c.Tables-1) > math.MaxInt32 {
+		return fmt.Errorf("seed %d out of range for %d tables", c.Seed, c.Tables)
+	}
+	if len(c.Strategies) != playerCount {
+		return fmt.Errorf("need %d strategies, got %d", playerCount, len(c.Strategies))
+	}
+	for _, name := range c.Strategies {
+		if _, err := ai.NewStrategy(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// gamesPerTable 每桌总局数
+func (c *Config) gamesPerTable() int32 {
+	return c.Deals * c.Rotations
+}
+
+// schedule 第seat座位在每次轮换中使用的策略
+func (c *Config) schedule(seat int) []string {
+	names := make([]string, c.Rotations)
+	for r := range names {
+		names[r] = c.Strategies[(seat+r)%playerCount]
+	}
+	return names
+}
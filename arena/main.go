@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/kevin-chtw/tw_common/gamebase/game"
+	"github.com/kevin-chtw/tw_common/utils"
+	"github.com/kevin-chtw/tw_mjsc_svr/ai"
+	"github.com/kevin-chtw/tw_mjsc_svr/bot"
+	"github.com/kevin-chtw/tw_mjsc_svr/conf"
+	"github.com/kevin-chtw/tw_mjsc_svr/mjsc"
+	"github.com/kevin-chtw/tw_proto/sproto"
+	"github.com/sirupsen/logrus"
+	pitaya "github.com/topfreegames/pitaya/v3/pkg"
+	"github.com/topfreegames/pitaya/v3/pkg/config"
+	"github.com/topfreegames/pitaya/v3/pkg/logger"
+	"github.com/topfreegames/pitaya/v3/pkg/serialize"
+)
+
+const (
+	playerCount = 4
+	matchType   = "arena"
+)
+
+var app pitaya.Pitaya
+
+func main() {
+	pitaya.SetLogger(utils.Logger(logrus.WarnLevel))
+
+	cfg, err := parseConfig(os.Args[1:])
+	if err != nil {
+		logger.Log.Fatalf("Invalid arena config: %v", err)
+	}
+
+	// 评测桌：不等动画、不记录训练数据
+	runCfg := conf.Default()
+	runCfg.AIAddr = cfg.AIAddr
+	runCfg.Tables[matchType] = conf.Profile{Mode: conf.ModeEvaluation}
+	conf.Set(runCfg)
+
+	if err := ai.InitHTTPAIClient(cfg.AIAddr); err != nil {
+		logger.Log.Fatalf("Failed to init AI client: %v", err)
+	}
+	defer ai.GetHTTPAIClient().Close()
+
+	a := newArena(cfg)
+	bot.SetResultHook(a.onResult)
+
+	serverType := utils.MJSC + "_arena"
+	config := config.NewDefaultPitayaConfig()
+	config.SerializerType = uint16(serialize.PROTOBUF)
+	config.Handler.Messages.Compression = false
+	builder := pitaya.NewDefaultBuilder(false, serverType, pitaya.Cluster, map[string]string{}, *config)
+	app = builder.Build()
+
+	game.Init(app, mjsc.NewGame, bot.NewPlayer)
+	go run(cfg, a)
+	app.Start()
+
+	runDir := filepath.Join(cfg.OutputDir, time.Now().Format("20060102-150405"))
+	if err := writeJSON(runDir, "report.json", a.report()); err != nil {
+		logger.Log.Errorf("Failed to write report: %v", err)
+	}
+}
+
+// run 每桌同一副牌墙连续打 Rotations 局，每局所有策略顺移一个座位
+func run(cfg *Config, a *arena) {
+	time.Sleep(time.Duration(cfg.StartDelayMs) * time.Millisecond)
+	for i := range cfg.Tables {
+		gameConfig, err := json.Marshal(map[string]int32{
+			"seed":       int32(cfg.Seed + int64(i)),
+			"dealrepeat": cfg.Rotations,
+		})
+		if err != nil {
+			logger.Log.Fatalf("Failed to marshal rules: %v", err)
+		}
+		table := game.GetTableManager().LoadOrStore(1, int32(i+1))
+		table.HandleAddTable(context.Background(), &sproto.AddTableReq{
+			ScoreBase:   1,
+			GameCount:   cfg.gamesPerTable(),
+			PlayerCount: playerCount,
+			MatchType:   matchType,
+			GameConfig:  string(gameConfig),
+		})
+		for j := range playerCount {
+			uid := strconv.Itoa(i*playerCount + j + 1)
+			bot.SetStrategySchedule(uid, cfg.schedule(j))
+			a.addBot(uid, i)
+			table.HandleAddPlayer(context.Background(), &sproto.AddPlayerReq{
+				Playerid: uid,
+				Bot:      true,
+				Seat:     int32(j),
+			})
+		}
+	}
+
+	<-a.done
+	logger.Log.Warnf("arena finished, %d tables", cfg.Tables)
+	app.Shutdown()
+}
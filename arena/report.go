@@ -0,0 +1,180 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/kevin-chtw/tw_mjsc_svr/bot"
+)
+
+// StrategyReport 单个策略的评测结果
+type StrategyReport struct {
+	Games         int     `json:"games"`
+	MeanScore     float64 `json:"mean_score"`      // 每局平均得分
+	CI95          float64 `json:"ci95"`            // 平均得分95%置信区间半宽
+	WinRate       float64 `json:"win_rate"`        // 胡牌率
+	DealInRate    float64 `json:"deal_in_rate"`    // 点炮率
+	AvgFan        float64 `json:"avg_fan"`         // 胡牌平均番数
+	DrawShanten   float64 `json:"draw_shanten"`    // 流局时未胡玩家平均向听数
+	DrawGameCount int     `json:"draw_game_count"` // 流局未胡局数
+}
+
+// Report 评测报告
+type Report struct {
+	StartTime  time.Time                  `json:"start_time"`
+	EndTime    time.Time                  `json:"end_time"`
+	Config     *Config                    `json:"config"`
+	Games      int                        `json:"games"`
+	Strategies map[string]*StrategyReport `json:"strategies"`
+}
+
+type strategyStats struct {
+	scores      []float64
+	wins        int
+	dealIns     int
+	fanSum      int64
+	drawShanten int
+	draws       int
+}
+
+// arena 收集各机器人每局结果
+type arena struct {
+	mu        sync.Mutex
+	cfg       *Config
+	start     time.Time
+	tables    map[string]int // uid -> 桌号
+	tableDone []int32
+	games     int
+	stats     map[string]*strategyStats
+	done      chan struct{}
+}
+
+func newArena(cfg *Config) *arena {
+	return &arena{
+		cfg:       cfg,
+		start:     time.Now(),
+		tables:    make(map[string]int),
+		tableDone: make([]int32, cfg.Tables),
+		stats:     make(map[string]*strategyStats),
+		done:      make(chan struct{}),
+	}
+}
+
+func (a *arena) addBot(uid string, table int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.tables[uid] = table
+}
+
+// onResult 每个机器人只统计自己座位，由0号座位计局数
+func (a *arena) onResult(res *bot.GameResult) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	table, ok := a.tables[res.Uid]
+	if !ok {
+		return
+	}
+	stats, ok := a.stats[res.Strategy]
+	if !ok {
+		stats = &strategyStats{}
+		a.stats[res.Strategy] = stats
+	}
+	for _, result := range res.Ack.PlayerResults {
+		if result.Seat == res.Seat {
+			stats.scores = append(stats.scores, float64(result.WinScore))
+		}
+	}
+	state := res.State
+	seat := int(res.Seat)
+	if slices.Contains(state.HuPlayers, seat) {
+		stats.wins++
+		stats.fanSum += state.HuMultis[seat]
+	} else if state.IsWallEmpty() {
+		stats.draws++
+		stats.drawShanten += state.FinalShanten()
+	}
+	if state.DealInCount > 0 {
+		stats.dealIns++
+	}
+
+	if res.Seat != 0 {
+		return
+	}
+	a.games++
+	a.tableDone[table]++
+	for _, n := range a.tableDone {
+		if n < a.cfg.gamesPerTable() {
+			return
+		}
+	}
+	select {
+	case <-a.done:
+	default:
+		close(a.done)
+	}
+}
+
+func (a *arena) report() *Report {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	r := &Report{
+		StartTime:  a.start,
+		EndTime:    time.Now(),
+		Config:     a.cfg,
+		Games:      a.games,
+		Strategies: make(map[string]*StrategyReport),
+	}
+	for name, stats := range a.stats {
+		n := len(stats.scores)
+		if n == 0 {
+			continue
+		}
+		sr := &StrategyReport{Games: n, DrawGameCount: stats.draws}
+		sr.MeanScore, sr.CI95 = meanCI(stats.scores)
+		sr.WinRate = float64(stats.wins) / float64(n)
+		sr.DealInRate = float64(stats.dealIns) / float64(n)
+		if stats.wins > 0 {
+			sr.AvgFan = float64(stats.fanSum) / float64(stats.wins)
+		}
+		if stats.draws > 0 {
+			sr.DrawShanten = float64(stats.drawShanten) / float64(stats.draws)
+		}
+		r.Strategies[name] = sr
+	}
+	return r
+}
+
+// meanCI 均值及正态近似的95%置信区间半宽
+func meanCI(xs []float64) (float64, float64) {
+	n := float64(len(xs))
+	var sum float64
+	for _, x := range xs {
+		sum += x
+	}
+	mean := sum / n
+	if len(xs) < 2 {
+		return mean, 0
+	}
+	var sq float64
+	for _, x := range xs {
+		sq += (x - mean) * (x - mean)
+	}
+	sd := math.Sqrt(sq / (n - 1))
+	return mean, 1.96 * sd / math.Sqrt(n)
+}
+
+func writeJSON(dir, name string, v any) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, name), data, 0o644)
+}
@@ -0,0 +1,37 @@
+package bot
+
+import (
+	"github.com/topfreegames/pitaya/v3/pkg/logger"
+	"github.com/topfreegames/pitaya/v3/pkg/logger/interfaces"
+)
+
+func newBotLogger(uid string, matchid, tableid int32) interfaces.Logger {
+	return logger.Log.WithFields(map[string]any{
+		"match": matchid,
+		"table": tableid,
+		"uid":   uid,
+	})
+}
+
+// logger 附带比赛类型、局数、座位和回合数的日志
+func (p *Player) logger() interfaces.Logger {
+	return p.log.WithFields(map[string]any{
+		"match_type": p.profile.MatchType,
+		"game":       p.games,
+		"seat":       p.Seat,
+		"turn":       p.turn,
+	})
+}
+
+// verbosef 手牌等详细日志，按 BotLogSample 采样输出
+func (p *Player) verbosef(format string, args ...any) {
+	sample := p.profile.BotLogSample
+	if sample <= 0 {
+		return
+	}
+	p.verboseCount++
+	if p.verboseCount%sample != 0 {
+		return
+	}
+	p.logger().Infof(format, args...)
+}
@@ -5,19 +5,32 @@ import (
 	"github.com/kevin-chtw/tw_common/gamebase/mahjong"
 	"github.com/kevin-chtw/tw_common/utils"
 	"github.com/kevin-chtw/tw_mjsc_svr/ai"
+	"github.com/kevin-chtw/tw_mjsc_svr/conf"
+	"github.com/kevin-chtw/tw_mjsc_svr/pbmjsc"
+	"github.com/kevin-chtw/tw_mjsc_svr/tracing"
 	"github.com/kevin-chtw/tw_proto/cproto"
 	"github.com/kevin-chtw/tw_proto/game/pbmj"
 	"github.com/kevin-chtw/tw_proto/game/pbsc"
-	"github.com/topfreegames/pitaya/v3/pkg/logger"
+	"github.com/topfreegames/pitaya/v3/pkg/logger/interfaces"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/anypb"
 )
 
+// Player 机器人玩家：状态由 GameState.Apply 统一维护，handlers 只负责决策
 type Player struct {
 	*game.BotPlayer
-	handlers    map[string]func(proto.Message) error
-	gameState   *ai.GameState
-	pendingReqs []*game.PendingReq
+	handlers     map[string]func(proto.Message) error
+	gameState    *ai.GameState
+	strategy     ai.Strategy
+	stratName    string
+	games        int
+	profile      *conf.Profile
+	pendingReqs  []*game.PendingReq
+	log          interfaces.Logger
+	turn         int32 // 本局第几手（已出牌数+1），与服务端日志对应
+	verboseCount int
 }
 
 func NewPlayer(uid string, matchid, tableid int32, scorebase int64) *game.BotPlayer {
@@ -25,7 +38,11 @@ func NewPlayer(uid string, matchid, tableid int32, scorebase int64) *game.BotPla
 		BotPlayer: game.NewBotPlayer(uid, matchid, tableid, scorebase),
 		handlers:  make(map[string]func(proto.Message) error),
 		gameState: ai.NewGameState(),
+		profile:   conf.SeatProfile(uid),
+		log:       newBotLogger(uid, matchid, tableid),
 	}
+	p.gameState.Meta = ai.EpisodeMeta{MatchID: matchid, TableID: tableid, Seed: tableSeed(uid)}
+	p.selectStrategy()
 
 	p.Bot = p
 	p.init()
@@ -37,17 +54,12 @@ func (p *Player) init() {
 	p.handlers[utils.TypeUrl(&pbmj.MJAnimationAck{})] = p.animationAck
 	p.handlers[utils.TypeUrl(&pbmj.MJOpenDoorAck{})] = p.openDoorAck
 	p.handlers[utils.TypeUrl(&pbsc.SCSwapTilesAck{})] = p.swapTileAck
-	p.handlers[utils.TypeUrl(&pbsc.SCSwapFinishAck{})] = p.swapFinishAck
 	p.handlers[utils.TypeUrl(&pbsc.SCSwapTilesResultAck{})] = p.swapResultAck
 	p.handlers[utils.TypeUrl(&pbsc.SCDingQueAck{})] = p.dingQueAck
-	p.handlers[utils.TypeUrl(&pbsc.SCDingQueResultAck{})] = p.dingQueResultAck
-	p.handlers[utils.TypeUrl(&pbmj.MJRequestAck{})] = p.requestAck
 	p.handlers[utils.TypeUrl(&pbmj.MJDiscardAck{})] = p.discardAck
-	p.handlers[utils.TypeUrl(&pbmj.MJDrawAck{})] = p.drawAck
-	p.handlers[utils.TypeUrl(&pbmj.MJHuAck{})] = p.huAck
-	p.handlers[utils.TypeUrl(&pbmj.MJKonAck{})] = p.konAck
-	p.handlers[utils.TypeUrl(&pbmj.MJPonAck{})] = p.ponAck
+	p.handlers[utils.TypeUrl(&pbmj.MJRequestAck{})] = p.requestAck
 	p.handlers[utils.TypeUrl(&pbmj.MJResultAck{})] = p.resultAck
+	p.handlers[utils.TypeUrl(&pbmjsc.SCErrorAck{})] = p.errorAck
 }
 
 func (p *Player) OnTimer() error {
@@ -56,7 +68,7 @@ func (p *Player) OnTimer() error {
 		p.pendingReqs[i].Delay -= 1000 // 每次减少1秒
 		if p.pendingReqs[i].Delay <= 0 {
 			if err := p.sendMsg(p.pendingReqs[i].Req); err != nil {
-				logger.Log.Errorf("发送请求失败: %v", err)
+				p.logger().Errorf("发送请求失败: %v", err)
 			}
 			p.pendingReqs = append(p.pendingReqs[:i], p.pendingReqs[i+1:]...)
 			i-- // 调整索引
@@ -89,6 +101,10 @@ func (p *Player) OnBotMsg(msg proto.Message) error {
 		if err != nil {
 			return err
 		}
+		p.gameState.Apply(inMsg)
+		if p.profile.Debug {
+			p.checkState()
+		}
 		h, ok := p.handlers[scAck.Ack.TypeUrl]
 		if ok {
 			return h(inMsg)
@@ -97,18 +113,28 @@ func (p *Player) OnBotMsg(msg proto.Message) error {
 	return nil
 }
 
+// checkState 调试模式下校验机器人视角与服务端状态，发现漂移时输出日志
+func (p *Player) checkState() {
+	view := ai.ProbeSeatView(p.Uid)
+	if view == nil {
+		return
+	}
+	if diffs := p.gameState.Diff(view); len(diffs) > 0 {
+		p.logger().Errorf("state drift: %v", diffs)
+	}
+}
+
 func (p *Player) delayMsg(req proto.Message) {
-	// 训练模式下立即发送，不延迟
-	if ai.IsTrainingMode() {
+	// 训练、评测桌立即发送，不延迟
+	if p.profile.Immediate() {
 		p.sendMsg(req)
 		return
 	}
 
-	// 生产模式：添加到待发送队列
-	delay := 0 //1000 + rand.Intn(1000) // 3-5秒随机延迟
+	// 正常桌：按配置延迟后发送
 	p.pendingReqs = append(p.pendingReqs, &game.PendingReq{
 		Req:   req,
-		Delay: delay,
+		Delay: p.profile.BotDelayMs,
 	})
 }
 
@@ -122,20 +148,27 @@ func (p *Player) sendMsg(msg proto.Message) error {
 }
 
 func (p *Player) gameStartAck(msg proto.Message) error {
-	p.gameState = ai.NewGameState()
+	p.profile = conf.SeatProfile(p.Uid)
+	p.selectStrategy()
+	p.games++
+	p.turn = 1
 	p.gameState.CurrentSeat = int(p.Seat)
+	p.gameState.ScoreBase = p.Scorebase
+	p.gameState.Learnable = p.profile.IsTraining()
 	return nil
 }
 
-func (p *Player) openDoorAck(msg proto.Message) error {
-	ack := msg.(*pbmj.MJOpenDoorAck)
-	if ack.Seat == int32(p.gameState.CurrentSeat) {
-		for _, tile := range ack.GetTiles() {
-			p.gameState.Hand[mahjong.Tile(tile)]++
-		}
-		p.gameState.TotalTiles -= (13*4 + 1)
+// selectStrategy 按本局序号选择策略（评测时轮换座位）
+func (p *Player) selectStrategy() {
+	name := strategyName(p.Uid, p.games)
+	if p.strategy == nil || name != p.stratName {
+		p.strategy = newStrategy(name)
+		p.stratName = name
 	}
-	logger.Log.Infof("seat=%d, %v", p.gameState.CurrentSeat, p.gameState.Hand)
+}
+
+func (p *Player) openDoorAck(msg proto.Message) error {
+	p.verbosef("hand %v", p.gameState.Hand)
 	return nil
 }
 
@@ -182,28 +215,8 @@ func (p *Player) swapTileAck(msg proto.Message) error {
 	return nil
 }
 
-func (p *Player) swapFinishAck(msg proto.Message) error {
-	ack := msg.(*pbsc.SCSwapFinishAck)
-	if ack.Seat == int32(p.gameState.CurrentSeat) {
-		for _, tile := range ack.GetTiles() {
-			p.gameState.Hand[mahjong.Tile(tile)]--
-		}
-	}
-	return nil
-}
-
 func (p *Player) swapResultAck(msg proto.Message) error {
-	ack := msg.(*pbsc.SCSwapTilesResultAck)
-	swaps := ack.GetSwapTiles()
-	for _, s := range swaps {
-		if s.To == int32(p.gameState.CurrentSeat) {
-			for _, t := range s.Tiles {
-				p.gameState.Hand[mahjong.Tile(t)]++
-			}
-		}
-	}
-
-	logger.Log.Infof("seat=%d, %v", p.gameState.CurrentSeat, p.gameState.Hand)
+	p.verbosef("hand after swap %v", p.gameState.Hand)
 	return nil
 }
 
@@ -232,11 +245,8 @@ func (p *Player) dingQueAck(msg proto.Message) error {
 	return nil
 }
 
-func (p *Player) dingQueResultAck(msg proto.Message) error {
-	ack := msg.(*pbsc.SCDingQueResultAck)
-	for i, c := range ack.GetColors() {
-		p.gameState.PlayerLacks[i] = mahjong.EColor(c)
-	}
+func (p *Player) discardAck(msg proto.Message) error {
+	p.turn++
 	return nil
 }
 
@@ -245,9 +255,18 @@ func (p *Player) requestAck(msg proto.Message) error {
 	if ack.Seat != int32(p.gameState.CurrentSeat) {
 		return nil
 	}
-	logger.Log.Info(p.gameState.Hand)
-	p.gameState.Operates = mahjong.NewOperates(ack.RequestType)
-	ret := ai.GetRichAI().Step(p.gameState)
+	p.verbosef("request %v, hand %v", ack.GetRequestType(), p.gameState.Hand)
+	ctx, span := tracing.Tracer().Start(tracing.SeatContext(p.Uid), "bot.decision", trace.WithAttributes(
+		attribute.String("uid", p.Uid),
+		attribute.Int("seat", int(p.Seat)),
+		attribute.Int("turn", int(p.turn)),
+		attribute.String("strategy", p.stratName),
+	))
+	ret := p.strategy.Step(ctx, p.gameState)
+	span.End()
+	if ret == nil {
+		return nil
+	}
 	req := &pbmj.MJRequestReq{
 		Seat:        ack.Seat,
 		RequestType: int32(ret.Operate),
@@ -258,74 +277,29 @@ func (p *Player) requestAck(msg proto.Message) error {
 	return nil
 }
 
-// 处理各种ACK并记录操作
-func (p *Player) discardAck(msg proto.Message) error {
-	ack := msg.(*pbmj.MJDiscardAck)
-
-	p.gameState.LastTile = mahjong.Tile(ack.Tile)
-	if ack.Seat == int32(p.gameState.CurrentSeat) {
-		p.gameState.Hand[mahjong.Tile(ack.Tile)]--
-	}
-
-	// 记录实现操作（出牌）
-	p.gameState.RecordAction(int(ack.Seat), mahjong.OperateDiscard, mahjong.Tile(ack.Tile))
-
-	return nil
-}
-
-func (p *Player) drawAck(msg proto.Message) error {
-	ack := msg.(*pbmj.MJDrawAck)
-	p.gameState.TotalTiles--
-	if ack.Seat == int32(p.gameState.CurrentSeat) {
-		p.gameState.LastTile = mahjong.Tile(ack.Tile)
-		p.gameState.Hand[mahjong.Tile(ack.Tile)]++
-		p.gameState.CallData = ack.CallData
-	}
-	return nil
-}
-
-func (p *Player) huAck(msg proto.Message) error {
-	ack := msg.(*pbmj.MJHuAck)
-	for _, h := range ack.HuData {
-		p.gameState.HuPlayers = append(p.gameState.HuPlayers, int(h.Seat))
-		p.gameState.HuMultis[int(h.Seat)] = h.Multi // 记录番数
-		p.gameState.RecordAction(int(h.Seat), mahjong.OperateHu, mahjong.Tile(ack.Tile))
-	}
-	return nil
-}
-
-func (p *Player) konAck(msg proto.Message) error {
-	ack := msg.(*pbmj.MJKonAck)
-	p.gameState.KonTiles[int(ack.Seat)] = append(p.gameState.KonTiles[int(ack.Seat)], mahjong.Tile(ack.Tile))
-	if ack.Seat == int32(p.gameState.CurrentSeat) {
-		p.gameState.Hand[mahjong.Tile(ack.Tile)] = 0
-	}
-	// 记录实现操作（杠牌）
-	p.gameState.RecordAction(int(ack.Seat), mahjong.OperateKon, mahjong.Tile(ack.Tile))
-	return nil
-}
-func (p *Player) ponAck(msg proto.Message) error {
-	ack := msg.(*pbmj.MJPonAck)
-	p.gameState.PonTiles[int(ack.Seat)] = append(p.gameState.PonTiles[int(ack.Seat)], mahjong.Tile(ack.Tile))
-	if ack.Seat == int32(p.gameState.CurrentSeat) {
-		p.gameState.Hand[mahjong.Tile(ack.Tile)] -= 2
-		p.gameState.CallData = ack.CallData
-	}
-	// 记录实现操作（碰牌）
-	p.gameState.RecordAction(int(ack.Seat), mahjong.OperatePon, mahjong.Tile(ack.Tile))
+// errorAck 机器人的请求被服务端拒绝，说明决策与服务端状态不一致
+func (p *Player) errorAck(msg proto.Message) error {
+	ack := msg.(*pbmjsc.SCErrorAck)
+	p.logger().Warnf("request %s rejected: code=%d %s", ack.ReqType, ack.Code, ack.Reason)
 	return nil
 }
 
 func (p *Player) resultAck(msg proto.Message) error {
 	ack := msg.(*pbmj.MJResultAck)
+	if resultHook != nil {
+		resultHook(&GameResult{
+			Uid:      p.Uid,
+			Seat:     p.Seat,
+			Strategy: p.stratName,
+			Ack:      ack,
+			State:    p.gameState,
+		})
+	}
 	used := false
 	for _, player := range ack.PlayerResults {
 		if player.WinScore != 0 {
 			used = true
 		}
-		if player.Seat == int32(p.gameState.CurrentSeat) {
-			p.gameState.FinalScore = float32(player.WinScore / p.Scorebase)
-		}
 	}
 
 	if used {
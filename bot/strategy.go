@@ -0,0 +1,72 @@
+package bot
+
+import (
+	"sync"
+
+	"github.com/kevin-chtw/tw_mjsc_svr/ai"
+	"github.com/kevin-chtw/tw_proto/game/pbmj"
+	"github.com/topfreegames/pitaya/v3/pkg/logger"
+)
+
+// GameResult 一局结束时机器人视角的结果
+type GameResult struct {
+	Uid      string
+	Seat     int32
+	Strategy string
+	Ack      *pbmj.MJResultAck
+	State    *ai.GameState
+}
+
+var (
+	schedules  sync.Map // uid -> []string 按局轮换的策略
+	seeds      sync.Map // uid -> int64 所在牌桌的种子
+	resultHook func(result *GameResult)
+)
+
+// SetStrategy 指定机器人（按uid）使用的策略，需在机器人入座前设置
+func SetStrategy(uid string, name string) {
+	SetStrategySchedule(uid, []string{name})
+}
+
+// SetStrategySchedule 指定机器人每局轮换使用的策略，第n局使用 names[n%len(names)]
+func SetStrategySchedule(uid string, names []string) {
+	schedules.Store(uid, names)
+}
+
+// SetSeed 指定机器人（按uid）所在牌桌的种子，写入轨迹元数据，需在机器人入座前设置
+func SetSeed(uid string, seed int64) {
+	seeds.Store(uid, seed)
+}
+
+// SetResultHook 每局结算后回调（训练/评测统计用）
+func SetResultHook(hook func(result *GameResult)) {
+	resultHook = hook
+}
+
+func strategyName(uid string, game int) string {
+	v, ok := schedules.Load(uid)
+	if !ok {
+		return ai.StrategyAI
+	}
+	names := v.([]string)
+	if len(names) == 0 {
+		return ai.StrategyAI
+	}
+	return names[game%len(names)]
+}
+
+func tableSeed(uid string) int64 {
+	if v, ok := seeds.Load(uid); ok {
+		return v.(int64)
+	}
+	return 0
+}
+
+func newStrategy(name string) ai.Strategy {
+	strategy, err := ai.NewStrategy(name)
+	if err != nil {
+		logger.Log.Errorf("%v, using %s", err, ai.StrategyAI)
+		return ai.GetRichAI()
+	}
+	return strategy
+}
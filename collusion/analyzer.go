@@ -0,0 +1,123 @@
+package collusion
+
+import (
+	"fmt"
+)
+
+const (
+	// Event.Kind
+	KindFeed    = 1 // 点炮：From 打出的牌被 To 胡
+	KindDecline = 2 // 弃胡：To 可以胡 From 的牌却没有胡
+	KindPassPon = 3 // 弃碰：To 可以碰 From 的牌却选择过
+	KindMeld    = 4 // 喂牌：To 碰、直杠了 From 的牌
+)
+
+// 可疑分数 = 各类事件的加权和 / 同桌局数
+const (
+	weightFeed    = 1.0
+	weightFeedFan = 0.1 // 点炮番数额外加权，故意放大番
+	weightDecline = 4.0 // 正常玩家极少放弃胡牌
+	weightPassPon = 0.5
+	weightMeld    = 0.25
+
+	Threshold = 1.5 // 跨局累计分数达到此值视为可疑
+	MinGames  = 20  // 同桌局数不足时不判定
+)
+
+// Event 一局中两个座位之间的一次事件
+type Event struct {
+	Kind int
+	From int32 // 打出牌的座位
+	To   int32 // 胡、碰或放弃的座位
+	Fan  int64 // 点炮、弃胡的番数
+}
+
+// Pair 两个账号，A < B
+type Pair struct {
+	A string
+	B string
+}
+
+func NewPair(a, b string) Pair {
+	if a > b {
+		a, b = b, a
+	}
+	return Pair{A: a, B: b}
+}
+
+func (p Pair) String() string {
+	return fmt.Sprintf("%s|%s", p.A, p.B)
+}
+
+// PairStats 两个账号之间的事件统计，不区分方向
+type PairStats struct {
+	Games    int   `json:"games"` // 同桌局数
+	Feeds    int   `json:"feeds"`
+	FeedFan  int64 `json:"feed_fan"`
+	Declines int   `json:"declines"`
+	PassPons int   `json:"pass_pons"`
+	Melds    int   `json:"melds"`
+}
+
+func (s *PairStats) add(o *PairStats) {
+	s.Games += o.Games
+	s.Feeds += o.Feeds
+	s.FeedFan += o.FeedFan
+	s.Declines += o.Declines
+	s.PassPons += o.PassPons
+	s.Melds += o.Melds
+}
+
+// Score 平均每局的可疑分数
+func (s *PairStats) Score() float64 {
+	if s.Games == 0 {
+		return 0
+	}
+	total := weightFeed*float64(s.Feeds) + weightFeedFan*float64(s.FeedFan) +
+		weightDecline*float64(s.Declines) + weightPassPon*float64(s.PassPons) + weightMeld*float64(s.Melds)
+	return total / float64(s.Games)
+}
+
+// Suspicious 累计局数足够且分数达到阈值
+func (s *PairStats) Suspicious() bool {
+	return s.Games >= MinGames && s.Score() >= Threshold
+}
+
+// Analyze 统计一局中每对账号之间的事件，players 为各座位uid
+func Analyze(players []string, events []Event) map[Pair]*PairStats {
+	stats := make(map[Pair]*PairStats)
+	for i := range players {
+		for j := i + 1; j < len(players); j++ {
+			if players[i] == "" || players[j] == "" || players[i] == players[j] {
+				continue
+			}
+			stats[NewPair(players[i], players[j])] = &PairStats{Games: 1}
+		}
+	}
+
+	for _, e := range events {
+		if !validSeat(players, e.From) || !validSeat(players, e.To) {
+			continue
+		}
+		s, ok := stats[NewPair(players[e.From], players[e.To])]
+		if !ok {
+			continue
+		}
+		switch e.Kind {
+		case KindFeed:
+			s.Feeds++
+			s.FeedFan += e.Fan
+		case KindDecline:
+			s.Declines++
+		case KindPassPon:
+			s.PassPons++
+		case KindMeld:
+			s.Melds++
+		}
+	}
+	return stats
+}
+
+func validSeat(players []string, seat int32) bool {
+	return seat >= 0 && int(seat) < len(players)
+}
@@ -0,0 +1,77 @@
+package collusion
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAnalyze(t *testing.T) {
+	players := []string{"a", "b", "c", "d"}
+	events := []Event{
+		{Kind: KindFeed, From: 0, To: 1, Fan: 8},
+		{Kind: KindFeed, From: 1, To: 0, Fan: 2}, // 不区分方向
+		{Kind: KindDecline, From: 2, To: 3},
+		{Kind: KindPassPon, From: 2, To: 3},
+		{Kind: KindMeld, From: 0, To: 2},
+		{Kind: KindFeed, From: 0, To: 9}, // 无效座位
+	}
+	stats := Analyze(players, events)
+	if len(stats) != 6 {
+		t.Fatalf("pairs = %d, want 6", len(stats))
+	}
+	for pair, st := range stats {
+		if st.Games != 1 {
+			t.Errorf("%s games = %d, want 1", pair, st.Games)
+		}
+	}
+
+	tests := []struct {
+		a, b string
+		want PairStats
+	}{
+		{"a", "b", PairStats{Games: 1, Feeds: 2, FeedFan: 10}},
+		{"d", "c", PairStats{Games: 1, Declines: 1, PassPons: 1}},
+		{"a", "c", PairStats{Games: 1, Melds: 1}},
+		{"b", "d", PairStats{Games: 1}},
+	}
+	for _, tt := range tests {
+		if got := stats[NewPair(tt.a, tt.b)]; got == nil || *got != tt.want {
+			t.Errorf("%s-%s = %+v, want %+v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestAnalyzeSkipsEmptyAndSameAccount(t *testing.T) {
+	stats := Analyze([]string{"a", "", "a", "b"}, []Event{{Kind: KindFeed, From: 0, To: 2}})
+	if len(stats) != 1 {
+		t.Fatalf("pairs = %v, want only a|b", stats)
+	}
+	if st := stats[NewPair("a", "b")]; st == nil || st.Feeds != 0 {
+		t.Errorf("a|b = %+v", st)
+	}
+}
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		name       string
+		stats      PairStats
+		score      float64
+		suspicious bool
+	}{
+		{"no games", PairStats{Feeds: 10}, 0, false},
+		{"clean", PairStats{Games: 40, Feeds: 10, FeedFan: 20, Melds: 8}, (10 + 2 + 2) / 40.0, false},
+		{"declines", PairStats{Games: 20, Declines: 8}, 32 / 20.0, true},
+		{"too few games", PairStats{Games: 19, Declines: 19}, 4, false},
+		{"at threshold", PairStats{Games: 20, Feeds: 20, PassPons: 20}, 1.5, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.stats.Score(); math.Abs(got-tt.score) > 1e-9 {
+				t.Errorf("Score() = %v, want %v", got, tt.score)
+			}
+			if got := tt.stats.Suspicious(); got != tt.suspicious {
+				t.Errorf("Suspicious() = %v, want %v", got, tt.suspicious)
+			}
+		})
+	}
+}
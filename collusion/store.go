@@ -0,0 +1,146 @@
+package collusion
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/topfreegames/pitaya/v3/pkg/logger"
+)
+
+const flushInterval = time.Minute
+
+// Store 跨局累计的账号对统计，保存在本地 JSON 文件中
+type Store struct {
+	mu    sync.Mutex
+	path  string
+	pairs map[string]*PairStats // Pair.String() -> 统计
+	dirty bool
+	done  chan struct{}
+}
+
+var store *Store
+
+// InitStore 打开全局存储，path 为空时不做分析
+func InitStore(path string) error {
+	if path == "" {
+		return nil
+	}
+	s, err := OpenStore(path)
+	if err != nil {
+		return err
+	}
+	store = s
+	return nil
+}
+
+// GetStore 获取全局存储，未初始化时为 nil
+func GetStore() *Store {
+	return store
+}
+
+// OpenStore 加载已有统计，并定期写回文件
+func OpenStore(path string) (*Store, error) {
+	s := &Store{
+		path:  path,
+		pairs: make(map[string]*PairStats),
+		done:  make(chan struct{}),
+	}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &s.pairs); err != nil {
+			return nil, err
+		}
+	}
+	go s.run()
+	return s, nil
+}
+
+// Add 累加一局的统计，返回这些账号对的累计结果
+func (s *Store) Add(stats map[Pair]*PairStats) map[Pair]PairStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	totals := make(map[Pair]PairStats, len(stats))
+	for pair, st := range stats {
+		key := pair.String()
+		total, ok := s.pairs[key]
+		if !ok {
+			total = &PairStats{}
+			s.pairs[key] = total
+		}
+		total.add(st)
+		totals[pair] = *total
+	}
+	s.dirty = len(stats) > 0 || s.dirty
+	return totals
+}
+
+// Get 查询两个账号的累计统计
+func (s *Store) Get(a, b string) PairStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if st, ok := s.pairs[NewPair(a, b).String()]; ok {
+		return *st
+	}
+	return PairStats{}
+}
+
+func (s *Store) run() {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.Flush(); err != nil {
+				logger.Log.Errorf("flush collusion store: %v", err)
+			}
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// Flush 有更新时写回文件（先写临时文件再替换）
+func (s *Store) Flush() error {
+	s.mu.Lock()
+	if !s.dirty {
+		s.mu.Unlock()
+		return nil
+	}
+	data, err := json.MarshalIndent(s.pairs, "", "  ")
+	s.dirty = false
+	s.mu.Unlock()
+	if err == nil {
+		err = s.write(data)
+	}
+	if err != nil {
+		// 写入失败时保留未写回标记，下次重试
+		s.mu.Lock()
+		s.dirty = true
+		s.mu.Unlock()
+	}
+	return err
+}
+
+func (s *Store) write(data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// Close 停止定期写回并写入最后的统计
+func (s *Store) Close() error {
+	close(s.done)
+	return s.Flush()
+}
@@ -0,0 +1,70 @@
+package collusion
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStoreAddFlushReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stats", "collusion.json")
+	s, err := OpenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ab := NewPair("b", "a")
+	s.Add(map[Pair]*PairStats{ab: {Games: 1, Feeds: 1, FeedFan: 4}})
+	totals := s.Add(map[Pair]*PairStats{ab: {Games: 1, Declines: 1}})
+	want := PairStats{Games: 2, Feeds: 1, FeedFan: 4, Declines: 1}
+	if totals[ab] != want {
+		t.Errorf("totals = %+v, want %+v", totals[ab], want)
+	}
+	if got := s.Get("a", "b"); got != want {
+		t.Errorf("Get = %+v, want %+v", got, want)
+	}
+	if got := s.Get("a", "c"); got != (PairStats{}) {
+		t.Errorf("Get unknown = %+v", got)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := OpenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	if got := reopened.Get("b", "a"); got != want {
+		t.Errorf("reloaded = %+v, want %+v", got, want)
+	}
+}
+
+func TestStoreFlushRetriesAfterError(t *testing.T) {
+	dir := t.TempDir()
+	blocker := filepath.Join(dir, "file")
+	if err := os.WriteFile(blocker, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := OpenStore(filepath.Join(dir, "collusion.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer close(s.done)
+	// 父目录是普通文件，写入必然失败
+	s.path = filepath.Join(blocker, "collusion.json")
+	s.Add(map[Pair]*PairStats{NewPair("a", "b"): {Games: 1}})
+	if err := s.Flush(); err == nil {
+		t.Fatal("Flush succeeded, want error")
+	}
+	if !s.dirty {
+		t.Error("dirty cleared after failed flush")
+	}
+
+	s.path = filepath.Join(dir, "collusion.json")
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if s.dirty {
+		t.Error("dirty after successful flush")
+	}
+}
@@ -0,0 +1,198 @@
+package conf
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	ModeProduction = "production" // 正常对局：播放动画、机器人模拟延迟
+	ModeTraining   = "training"   // 训练对局：跳过动画、机器人立即响应、记录决策
+	ModeEvaluation = "evaluation" // 评测对局：同训练对局但不记录决策
+)
+
+// Profile 单桌运行配置，按比赛类型选择
+type Profile struct {
+	Mode          string `json:"mode"`
+	AnimationWait bool   `json:"animation_wait"` // 是否等待客户端动画
+	BotDelayMs    int    `json:"bot_delay_ms"`   // 机器人响应延迟
+	Debug         bool   `json:"debug"`          // 机器人每步校验自身状态与服务端是否一致
+	Spectate      bool   `json:"spectate"`       // 是否允许观战，管理员不受限制
+	SpectateDelay int    `json:"spectate_delay"` // 观战延迟（秒），管理员全信息观战不延迟
+	BotLogSample  int    `json:"bot_log_sample"` // 机器人手牌等详细日志每 N 条输出一条，0 不输出
+	MatchType     string `json:"-"`              // 所属比赛类型，按比赛类型选择配置时填写
+}
+
+func (p *Profile) IsTraining() bool {
+	return p.Mode == ModeTraining
+}
+
+// Immediate 机器人是否立即响应（训练、评测）
+func (p *Profile) Immediate() bool {
+	return p.Mode != ModeProduction
+}
+
+// Config 进程级配置，优先级：默认值 < 配置文件 < 环境变量 < 命令行参数
+type Config struct {
+	AIAddr         string             `json:"ai_addr"`
+	LogLevel       string             `json:"log_level"`
+	Default        Profile            `json:"default"`
+	Tables         map[string]Profile `json:"tables"`          // 比赛类型 -> 单桌配置
+	Admins         []string           `json:"admins"`          // 可全信息观战（看手牌）的管理员uid
+	Frontend       string             `json:"frontend"`        // 观战消息推送的前端服务器类型
+	Trace          string             `json:"trace"`           // trace 导出方式：空(不采集) | stdout
+	CollusionStore string             `json:"collusion_store"` // 防串通统计文件，为空时不做分析
+}
+
+func Default() *Config {
+	return &Config{
+		AIAddr:   "localhost:50051",
+		LogLevel: "info",
+		Frontend: "proxy",
+		Default: Profile{
+			Mode:          ModeProduction,
+			AnimationWait: true,
+			Spectate:      true,
+			SpectateDelay: 30,
+			BotLogSample:  1,
+		},
+		Tables: map[string]Profile{
+			"trainer": {Mode: ModeTraining, BotLogSample: 100},
+		},
+	}
+}
+
+// Profile 返回比赛类型对应的单桌配置，未配置时使用默认配置
+func (c *Config) Profile(matchType string) *Profile {
+	p, ok := c.Tables[matchType]
+	if !ok {
+		p = c.Default
+	}
+	p.MatchType = matchType
+	return &p
+}
+
+func (c *Config) Level() logrus.Level {
+	level, err := logrus.ParseLevel(c.LogLevel)
+	if err != nil {
+		return logrus.InfoLevel
+	}
+	return level
+}
+
+// Load 从命令行参数、环境变量(MJSC_*)和配置文件加载配置
+func Load(args []string) (*Config, error) {
+	cfg := Default()
+	fs := flag.NewFlagSet("mjsc", flag.ContinueOnError)
+	path := fs.String("config", os.Getenv("MJSC_CONFIG"), "json config file")
+	mode := fs.String("mode", "", "default table mode: production | training")
+	aiAddr := fs.String("ai", "", "python AI service address")
+	logLevel := fs.String("log-level", "", "log level")
+	aniWait := fs.String("ani-wait", "", "wait for client animations on default tables: true | false, defaults to mode")
+	trace := fs.String("trace", "", "trace exporter: stdout")
+	collusionStore := fs.String("collusion-store", "", "anti-collusion stats file, empty disables")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	aniWaitSet := false
+	if *path != "" {
+		data, err := os.ReadFile(*path)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("parse config %s: %w", *path, err)
+		}
+		var probe struct {
+			Default struct {
+				AnimationWait *bool `json:"animation_wait"`
+			} `json:"default"`
+		}
+		if err := json.Unmarshal(data, &probe); err != nil {
+			return nil, fmt.Errorf("parse config %s: %w", *path, err)
+		}
+		aniWaitSet = probe.Default.AnimationWait != nil
+	}
+
+	override(&cfg.Default.Mode, os.Getenv("MJSC_MODE"), *mode)
+	override(&cfg.AIAddr, os.Getenv("MJSC_AI_ADDR"), *aiAddr)
+	override(&cfg.LogLevel, os.Getenv("MJSC_LOG_LEVEL"), *logLevel)
+	override(&cfg.Trace, os.Getenv("MJSC_TRACE"), *trace)
+	override(&cfg.CollusionStore, os.Getenv("MJSC_COLLUSION_STORE"), *collusionStore)
+	wait := ""
+	override(&wait, os.Getenv("MJSC_ANI_WAIT"), *aniWait)
+	if wait != "" {
+		v, err := strconv.ParseBool(wait)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ani-wait %q: %w", wait, err)
+		}
+		cfg.Default.AnimationWait = v
+	} else if !aniWaitSet {
+		// 未显式配置时按模式决定：只有正常对局等待动画
+		cfg.Default.AnimationWait = cfg.Default.Mode == ModeProduction
+	}
+	return cfg, cfg.validate()
+}
+
+func override(dst *string, values ...string) {
+	for _, v := range values {
+		if v != "" {
+			*dst = v
+		}
+	}
+}
+
+func (c *Config) validate() error {
+	profiles := map[string]Profile{"default": c.Default}
+	for k, v := range c.Tables {
+		profiles[k] = v
+	}
+	for name, p := range profiles {
+		if p.Mode != ModeProduction && p.Mode != ModeTraining && p.Mode != ModeEvaluation {
+			return fmt.Errorf("table %s: invalid mode %q", name, p.Mode)
+		}
+		if p.BotLogSample < 0 {
+			return fmt.Errorf("table %s: invalid bot_log_sample %d", name, p.BotLogSample)
+		}
+	}
+	return nil
+}
+
+var (
+	current = Default()
+	seats   sync.Map // uid -> *Profile
+)
+
+func Set(cfg *Config) {
+	current = cfg
+}
+
+func Get() *Config {
+	return current
+}
+
+// IsAdmin 是否管理员
+func (c *Config) IsAdmin(uid string) bool {
+	return slices.Contains(c.Admins, uid)
+}
+
+// BindSeat 牌局开始时登记玩家所在桌的配置，供机器人查询
+func BindSeat(uid string, profile *Profile) {
+	seats.Store(uid, profile)
+}
+
+// SeatProfile 查询玩家所在桌的配置，未登记时使用默认配置
+func SeatProfile(uid string) *Profile {
+	if v, ok := seats.Load(uid); ok {
+		return v.(*Profile)
+	}
+	return current.Profile("")
+}
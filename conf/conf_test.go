@@ -0,0 +1,51 @@
+package conf
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadAnimationWait(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		env    map[string]string
+		config string
+		want   bool
+	}{
+		{"default", nil, nil, "", true},
+		{"training flag", []string{"-mode", "training"}, nil, "", false},
+		{"evaluation env", nil, map[string]string{"MJSC_MODE": "evaluation"}, "", false},
+		{"explicit flag", []string{"-mode", "training", "-ani-wait", "true"}, nil, "", true},
+		{"explicit env", []string{"-mode", "training"}, map[string]string{"MJSC_ANI_WAIT": "true"}, "", true},
+		{"config mode", nil, nil, `{"default":{"mode":"training"}}`, false},
+		{"config explicit", []string{"-mode", "training"}, nil, `{"default":{"mode":"production","animation_wait":true}}`, true},
+		{"config production no wait", nil, nil, `{"default":{"animation_wait":false}}`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"MJSC_CONFIG", "MJSC_MODE", "MJSC_ANI_WAIT"} {
+				t.Setenv(key, "")
+			}
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			args := tt.args
+			if tt.config != "" {
+				path := filepath.Join(t.TempDir(), "mjsc.json")
+				if err := os.WriteFile(path, []byte(tt.config), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				args = append([]string{"-config", path}, args...)
+			}
+			cfg, err := Load(args)
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if cfg.Default.AnimationWait != tt.want {
+				t.Errorf("AnimationWait = %v, want %v (mode %s)", cfg.Default.AnimationWait, tt.want, cfg.Default.Mode)
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"context"
+	"os"
 	"strings"
 
 	"github.com/kevin-chtw/tw_common/gamebase/game"
@@ -8,8 +10,10 @@ import (
 	"github.com/kevin-chtw/tw_common/utils"
 	"github.com/kevin-chtw/tw_mjsc_svr/ai"
 	"github.com/kevin-chtw/tw_mjsc_svr/bot"
+	"github.com/kevin-chtw/tw_mjsc_svr/collusion"
+	"github.com/kevin-chtw/tw_mjsc_svr/conf"
 	"github.com/kevin-chtw/tw_mjsc_svr/mjsc"
-	"github.com/sirupsen/logrus"
+	"github.com/kevin-chtw/tw_mjsc_svr/tracing"
 	pitaya "github.com/topfreegames/pitaya/v3/pkg"
 	"github.com/topfreegames/pitaya/v3/pkg/component"
 	"github.com/topfreegames/pitaya/v3/pkg/config"
@@ -20,17 +24,35 @@ import (
 var app pitaya.Pitaya
 
 func main() {
-	// 关闭训练模式（仅推理）
-	ai.SetTrainingMode(true)
+	// 运行配置：各桌按比赛类型选择正常/训练模式
+	cfg, err := conf.Load(os.Args[1:])
+	if err != nil {
+		logger.Log.Fatalf("Invalid config: %v", err)
+	}
+	conf.Set(cfg)
+	pitaya.SetLogger(utils.Logger(cfg.Level()))
+
+	shutdownTrace, err := tracing.Init(cfg.Trace, os.Stdout)
+	if err != nil {
+		logger.Log.Fatalf("Failed to init tracing: %v", err)
+	}
+	defer shutdownTrace(context.Background())
 
 	// 初始化 Python AI 服务客户端
-	if err := ai.InitHTTPAIClient("localhost:50051"); err != nil {
+	if err := ai.InitHTTPAIClient(cfg.AIAddr); err != nil {
 		logger.Log.Fatalf("Failed to init AI client: %v", err)
 	}
 	defer ai.GetHTTPAIClient().Close()
 
+	// 正常对局的防串通统计
+	if err := collusion.InitStore(cfg.CollusionStore); err != nil {
+		logger.Log.Fatalf("Failed to open collusion store: %v", err)
+	}
+	if store := collusion.GetStore(); store != nil {
+		defer store.Close()
+	}
+
 	serverType := utils.MJSC
-	pitaya.SetLogger(utils.Logger(logrus.InfoLevel))
 
 	config := config.NewDefaultPitayaConfig()
 	config.SerializerType = uint16(serialize.PROTOBUF)
@@ -52,4 +74,7 @@ func initServices() {
 
 	playersvc := service.NewPlayer(app)
 	app.Register(playersvc, component.WithName("player"), component.WithNameFunc(strings.ToLower))
+
+	spectator := mjsc.NewSpectatorService(app)
+	app.Register(spectator, component.WithName("spectator"), component.WithNameFunc(strings.ToLower))
 }
@@ -0,0 +1,122 @@
+package metrics
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// 注册到默认 registry，由 pitaya 的 Prometheus 端点一并导出
+const namespace = "mjsc"
+
+var (
+	GamesStarted = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "games_started_total",
+		Help:      "Games started by match type.",
+	}, []string{"match_type"})
+
+	GamesFinished = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "games_finished_total",
+		Help:      "Games finished by match type.",
+	}, []string{"match_type"})
+
+	StateDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "state_duration_seconds",
+		Help:      "Time spent in each waiting state.",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 2, 12),
+	}, []string{"state"})
+
+	StateTimeouts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "state_timeouts_total",
+		Help:      "State timeouts by state.",
+	}, []string{"state"})
+
+	TrustActivations = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "trust_activations_total",
+		Help:      "Times a player was put on trust (auto play).",
+	})
+
+	HuTypes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "hu_types_total",
+		Help:      "Hu type occurrences.",
+	}, []string{"hu_type"})
+
+	HuFan = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "hu_fan",
+		Help:      "Fan (multiple) of each hu.",
+		Buckets:   prometheus.ExponentialBuckets(1, 2, 10),
+	})
+
+	LedgerViolations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "ledger_violations_total",
+		Help:      "Score changes or results that do not sum to zero.",
+	}, []string{"kind"})
+
+	AIDecisionLatency = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "ai_decision_seconds",
+		Help:      "Latency of AI service decisions.",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 2, 14),
+	})
+
+	AIFallbacks = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "ai_fallbacks_total",
+		Help:      "AI decisions replaced by the first candidate.",
+	}, []string{"reason"})
+
+	Rejections = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rejections_total",
+		Help:      "Player requests rejected by reason.",
+	}, []string{"code"})
+
+	RequestRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "request_retries_total",
+		Help:      "Duplicate player requests answered by replaying the previous ack.",
+	}, []string{"request"})
+
+	Episodes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "episodes_total",
+		Help:      "Training episodes written by sink and result.",
+	}, []string{"sink", "result"})
+)
+
+const (
+	// AIFallbacks reason
+	FallbackNoClient = "no_client"
+	FallbackError    = "error"
+	FallbackInvalid  = "invalid"
+
+	// LedgerViolations kind
+	LedgerScoreChange = "score_change"
+	LedgerResult      = "result"
+)
+
+// ObserveHu 记录一次胡牌的番型和番数
+func ObserveHu(huTypes []int32, multi int64) {
+	for _, t := range huTypes {
+		HuTypes.WithLabelValues(strconv.Itoa(int(t))).Inc()
+	}
+	HuFan.Observe(float64(multi))
+}
+
+// Episode 记录一次轨迹写入结果
+func Episode(sink string, err error) {
+	result := "ok"
+	if err != nil {
+		result = "failed"
+	}
+	Episodes.WithLabelValues(sink, result).Inc()
+}
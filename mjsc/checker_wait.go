@@ -15,6 +15,9 @@ func (c *checkerPao) Check(seat int32, opt *mahjong.Operates) {
 	if tile.Color() == c.play.getQueTile(seat).Color() {
 		return
 	}
+	if c.play.isHuLocked(seat, tile) {
+		return
+	}
 	c.checker.Check(seat, opt)
 }
 
@@ -31,6 +34,9 @@ func (c *checkerPon) Check(seat int32, opt *mahjong.Operates) {
 	if tile.Color() == c.play.getQueTile(seat).Color() {
 		return
 	}
+	if c.play.isPonLocked(seat, tile) {
+		return
+	}
 	c.checker.Check(seat, opt)
 }
 
@@ -0,0 +1,34 @@
+package mjsc
+
+import (
+	"github.com/kevin-chtw/tw_mjsc_svr/collusion"
+)
+
+// analyzeCollusion 正常对局结束后统计各账号对之间的事件，累计分数可疑时告警
+func (g *Game) analyzeCollusion() {
+	store := collusion.GetStore()
+	if store == nil || g.profile.Immediate() {
+		return
+	}
+	players := make([]string, g.GetPlayerCount())
+	for seat := range g.GetPlayerCount() {
+		players[seat] = g.GetPlayer(seat).Uid
+	}
+
+	stats := collusion.Analyze(players, g.play.events)
+	for pair, total := range store.Add(stats) {
+		if !total.Suspicious() {
+			continue
+		}
+		g.logger().WithFields(map[string]any{
+			"pair":       pair.String(),
+			"score":      total.Score(),
+			"game_score": stats[pair].Score(),
+			"games":      total.Games,
+			"feeds":      total.Feeds,
+			"declines":   total.Declines,
+			"pass_pons":  total.PassPons,
+			"melds":      total.Melds,
+		}).Warnf("suspected collusion")
+	}
+}
@@ -0,0 +1,86 @@
+package mjsc
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/kevin-chtw/tw_common/gamebase/mahjong"
+	"github.com/kevin-chtw/tw_mjsc_svr/ai"
+)
+
+// 调试模式下按玩家uid登记当前牌局的状态快照，供机器人校验状态
+var debugGames sync.Map
+
+// debugEntry 快照由牌桌协程在发消息时生成，机器人协程只读快照，不直接访问 Game
+type debugEntry struct {
+	game *Game
+	view atomic.Pointer[ai.SeatView]
+}
+
+func init() {
+	ai.SetSeatViewProbe(seatView)
+}
+
+func (g *Game) registerDebug() {
+	if !g.profile.Debug {
+		return
+	}
+	for seat := range g.GetPlayerCount() {
+		debugGames.Store(g.GetPlayer(seat).Uid, &debugEntry{game: g})
+	}
+}
+
+// unregisterDebug 终局时删除本局登记，玩家已进入新牌局的不删除
+func (g *Game) unregisterDebug() {
+	if !g.profile.Debug {
+		return
+	}
+	for seat := range g.GetPlayerCount() {
+		uid := g.GetPlayer(seat).Uid
+		if v, ok := debugGames.Load(uid); ok && v.(*debugEntry).game == g {
+			debugGames.CompareAndDelete(uid, v)
+		}
+	}
+}
+
+// publishDebug 消息发出前刷新各座位快照，保证机器人收到消息时快照不落后于消息
+func (g *Game) publishDebug() {
+	if !g.profile.Debug {
+		return
+	}
+	for seat := range g.GetPlayerCount() {
+		uid := g.GetPlayer(seat).Uid
+		if v, ok := debugGames.Load(uid); ok && v.(*debugEntry).game == g {
+			v.(*debugEntry).view.Store(g.seatView(uid))
+		}
+	}
+}
+
+func seatView(uid string) *ai.SeatView {
+	v, ok := debugGames.Load(uid)
+	if !ok {
+		return nil
+	}
+	return v.(*debugEntry).view.Load()
+}
+
+func (g *Game) seatView(uid string) *ai.SeatView {
+	view := &ai.SeatView{
+		PonTiles:      make(map[int][]mahjong.Tile),
+		KonTiles:      make(map[int][]mahjong.Tile),
+		WallRemaining: int(g.play.dealer.GetRestCount()),
+	}
+	for seat := range g.GetPlayerCount() {
+		playData := g.play.GetPlayData(seat)
+		if g.GetPlayer(seat).Uid == uid {
+			view.Hand = append([]mahjong.Tile(nil), playData.GetHandTiles()...)
+		}
+		for _, pon := range playData.GetPonGroups() {
+			view.PonTiles[int(seat)] = append(view.PonTiles[int(seat)], pon.Tile)
+		}
+		for _, kon := range playData.GetKonGroups() {
+			view.KonTiles[int(seat)] = append(view.KonTiles[int(seat)], kon.Tile)
+		}
+	}
+	return view
+}
@@ -23,5 +23,11 @@ const (
 	RuleDianKHSDP   = 19   //点杠花算点炮
 	RuleJueZhang    = 20   //绝张
 	RuleJiangDui258 = 21   //将对258
+	RuleWallSeed    = 22   //牌墙种子(0为随机)
+	RuleDealRepeat  = 23   //同一牌墙连续使用局数(复式轮换座位)
+	RuleJieHu       = 24   //截胡(一炮多响时只有点炮者下家方向第一个胡)
+	RuleZhuanYuFen  = 25   //呼叫转移分配方式(0平分 1每家全额 2给第一个胡牌者)
+	RuleGuoHu       = 26   //过手胡(0不限制 1摸牌前不能胡同一张 2摸牌前不能胡番数不高于放弃的牌)
+	RuleGuoPon      = 27   //过碰(摸牌前不能碰放弃过的牌)
 	RuleEnd         = iota //结束
 )
@@ -0,0 +1,76 @@
+package mjsc
+
+const MatchDuplicate = "duplicate" // 复式赛：同一副牌墙轮换牌位打多局
+
+// dupTurn 某座位在某次轮换中坐在该牌位的得分
+type dupTurn struct {
+	seat  int32
+	score int64
+}
+
+func (g *Game) isDuplicate() bool {
+	return g.MatchType == MatchDuplicate
+}
+
+// dealRepeat 同一副牌墙连续使用的局数，复式赛默认每个座位轮一次
+func (g *Game) dealRepeat() int32 {
+	repeat := int32(g.GetRule().GetValue(RuleDealRepeat))
+	if g.isDuplicate() && repeat <= 1 {
+		return g.GetPlayerCount()
+	}
+	return max(repeat, 1)
+}
+
+// rotation 当前牌墙的第几次轮换
+func (g *Game) rotation() int32 {
+	return (g.index - 1) % g.dealRepeat()
+}
+
+// duplicateBanker 复式赛每次轮换庄家顺移一位，同一牌墙下各牌位的手牌和摸牌顺序不变，
+// 相当于玩家依次坐到每个牌位
+func (g *Game) duplicateBanker() int32 {
+	return g.rotation() % g.GetPlayerCount()
+}
+
+// settleDuplicate 复式赛每局记下各牌位得分，牌墙轮换完后每人与同牌位其他人的平均分比较
+func (g *Game) settleDuplicate() {
+	if !g.isDuplicate() {
+		return
+	}
+	count := g.GetPlayerCount()
+	m := g.match
+	if len(m.dealTurns) != int(count) {
+		m.dealTurns = make([][]dupTurn, count)
+	}
+	if len(m.dupTotals) != int(count) {
+		m.dupTotals = make([]int64, count)
+	}
+
+	banker := g.duplicateBanker()
+	for seat := range count {
+		pos := (seat - banker + count) % count
+		m.dealTurns[pos] = append(m.dealTurns[pos], dupTurn{seat: seat, score: g.results[seat]})
+	}
+	if g.rotation() != g.dealRepeat()-1 {
+		return
+	}
+
+	scores := make([]int64, count)
+	for _, turns := range m.dealTurns {
+		if len(turns) < 2 {
+			continue
+		}
+		sum := int64(0)
+		for _, t := range turns {
+			sum += t.score
+		}
+		for _, t := range turns {
+			scores[t.seat] += t.score - (sum-t.score)/int64(len(turns)-1)
+		}
+	}
+	for seat, score := range scores {
+		m.dupTotals[seat] += score
+	}
+	m.dealTurns = nil
+	g.sender.sendDuplicateResultAck((g.index-1)/g.dealRepeat()+1, scores, m.dupTotals)
+}
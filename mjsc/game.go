@@ -1,14 +1,22 @@
 package mjsc
 
 import (
+	"context"
 	"errors"
+	"math/rand"
+	"time"
 
 	"github.com/kevin-chtw/tw_common/gamebase/game"
 	"github.com/kevin-chtw/tw_common/gamebase/mahjong"
 	"github.com/kevin-chtw/tw_common/utils"
+	"github.com/kevin-chtw/tw_mjsc_svr/conf"
+	"github.com/kevin-chtw/tw_mjsc_svr/metrics"
+	"github.com/kevin-chtw/tw_mjsc_svr/pbmjsc"
 	"github.com/kevin-chtw/tw_proto/game/pbmj"
 	"github.com/kevin-chtw/tw_proto/game/pbsc"
-	"github.com/topfreegames/pitaya/v3/pkg/logger"
+	"github.com/topfreegames/pitaya/v3/pkg/logger/interfaces"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/proto"
 )
 
 type Game struct {
@@ -16,10 +24,40 @@ type Game struct {
 	play       *Play
 	sender     *Sender
 	scorelator *mahjong.ScorelatorMany
+	profile    *conf.Profile
+	index      int32      // 本桌第几局（从1开始）
+	rand       *rand.Rand // 本局随机数（换三张方向等），设置牌墙种子时可复现
+	table      *game.Table
+	match      *match
+	results    []int64    // 本局各座位输赢
+	hus        []huRecord // 本局胡牌记录
+	lastKon    *konScore  // 最近一次杠分（呼叫转移用）
+	movedKons  []konScore // 已呼叫转移的杠分，流局时不再退杠
+	deadline   time.Time  // 当前状态超时时间
+	timedState string     // 正在统计耗时的等待状态
+	stateStart time.Time
+	observed   proto.Message // 上一条统计过的消息（同一消息发给多个座位时只统计一次）
+	log        interfaces.Logger
+	turn       int32 // 本局第几手（已出牌数+1）
+	gameCtx    context.Context
+	gameSpan   trace.Span
+	stateSpan  trace.Span
+	lastReqs   map[int32]*acceptedReq // 各座位最近一次被接受的请求，用于识别重发
+	capture    *acceptedReq           // 正在处理的请求
+	replaying  bool                   // 正在重发应答，不重复统计
 }
 
 func NewGame(t *game.Table, id int32) game.IGame {
-	g := &Game{}
+	g := &Game{
+		profile:  conf.Get().Profile(""),
+		index:    id,
+		rand:     rand.New(rand.NewSource(time.Now().UnixNano())),
+		table:    t,
+		match:    loadMatch(t, id),
+		log:      newGameLogger(t, id),
+		turn:     1,
+		lastReqs: make(map[int32]*acceptedReq),
+	}
 	g.Game = mahjong.NewGame(g, t, id)
 	g.play = NewPlay(g)
 	g.sender = NewSender(g)
@@ -29,7 +67,63 @@ func NewGame(t *game.Table, id int32) game.IGame {
 }
 
 func (g *Game) OnStart() {
-	g.Game.SetNextState(NewStateInit)
+	g.startGameSpan()
+	g.SetNextState(NewStateInit)
+}
+
+// enterState 构造新状态时调用：统计上一个等待状态耗时，记录状态 span。
+// 在状态构造而不是 SetNextState 中处理，基类发起的状态切换同样会统计
+func (g *Game) enterState(name string) {
+	g.observeState()
+	g.capture = nil
+	g.endStateSpan()
+	g.startStateSpan(name)
+}
+
+func (g *Game) observeState() {
+	if g.timedState == "" {
+		return
+	}
+	metrics.StateDuration.WithLabelValues(g.timedState).Observe(time.Since(g.stateStart).Seconds())
+	g.timedState = ""
+}
+
+// OnGameOver 覆盖基类方法：先做跨局统计和防串通分析
+func (g *Game) OnGameOver() {
+	g.observeState()
+	metrics.GamesFinished.WithLabelValues(g.MatchType).Inc()
+	g.settleMatch()
+	g.settleDuplicate()
+	g.analyzeCollusion()
+	g.endGameSpan()
+	g.unregisterDebug()
+	g.closeSpectators()
+	g.Game.OnGameOver()
+}
+
+// bindProfile 按比赛类型选择本桌配置并登记到各座位
+func (g *Game) bindProfile() {
+	g.profile = conf.Get().Profile(g.MatchType)
+	for seat := range g.GetPlayerCount() {
+		conf.BindSeat(g.GetPlayer(seat).Uid, g.profile)
+		seatTables.Store(g.GetPlayer(seat).Uid, seatTable{table: g.table, profile: g.profile})
+	}
+}
+
+// seedWall 设置了牌墙种子时，每 RuleDealRepeat 局使用同一副牌墙，用于复式对局消除运气
+func (g *Game) seedWall() {
+	seed := int64(g.GetRule().GetValue(RuleWallSeed))
+	if seed == 0 && g.isDuplicate() {
+		seed = g.match.seed
+	}
+	if seed == 0 {
+		return
+	}
+	dealSeed := seed*1000003 + int64((g.index-1)/g.dealRepeat())
+	g.rand = rand.New(rand.NewSource(dealSeed))
+	if !setDealerSeed(g.play.dealer, dealSeed) {
+		g.logger().Warnf("dealer does not support seeds, wall %d not replayed", dealSeed)
+	}
 }
 
 func (g *Game) OnReqMsg(player *game.Player, data []byte) error {
@@ -37,7 +131,7 @@ func (g *Game) OnReqMsg(player *game.Player, data []byte) error {
 	if err := utils.Unmarshal(player.Ctx, data, &msg); err != nil {
 		return err
 	}
-	logger.Log.Infof("seat %d recive msg %v", player.GetSeat(), &msg)
+	g.logger().WithField("seat", player.GetSeat()).Infof("recive msg %v", &msg)
 	req, err := msg.Req.UnmarshalNew()
 	if err != nil {
 		return err
@@ -48,9 +142,25 @@ func (g *Game) OnReqMsg(player *game.Player, data []byte) error {
 		g.sender.SendTrustAck(player.GetSeat(), false)
 		return nil
 	}
+	if intent, ok := req.(*pbmjsc.SCIntentReq); ok && g.IsValidSeat(player.GetSeat()) {
+		g.setIntent(player.GetSeat(), intent)
+		return nil
+	}
+	if pref, ok := req.(*pbmjsc.SCPreferenceReq); ok && g.IsValidSeat(player.GetSeat()) {
+		g.setPreference(player.GetSeat(), pref)
+		return nil
+	}
 
-	if g.CurState == nil {
-		return errors.New("invalid state")
+	if g.replayDuplicate(player.GetSeat(), req) {
+		return nil
+	}
+	err = reject(RejectInvalidState, "game not started")
+	if g.CurState != nil {
+		err = g.handleReq(player.GetSeat(), req)
+	}
+	var rej *RejectError
+	if errors.As(err, &rej) && g.IsValidSeat(player.GetSeat()) {
+		g.onReject(player.GetSeat(), req, rej)
 	}
-	return g.CurState.OnPlayerMsg(player.GetSeat(), req)
+	return err
 }
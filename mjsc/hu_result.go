@@ -9,15 +9,16 @@ import (
 
 const (
 	// huMode
-	PaoHu      = 1 //点炮胡
-	ZiMo       = 2 //自摸胡
-	KonKai     = 3 //杠开
-	KonPao     = 4 //杠炮
-	QiangKonHu = 5 //抢杠胡
-	HaiDi      = 6 //海底
-	HaiDiPao   = 7 //海底炮
-	TianHu     = 8 //天胡
-	DiHu       = 9 //地胡
+	PaoHu      = 1  //点炮胡
+	ZiMo       = 2  //自摸胡
+	KonKai     = 3  //杠开
+	KonPao     = 4  //杠炮
+	QiangKonHu = 5  //抢杠胡
+	HaiDi      = 6  //海底
+	HaiDiPao   = 7  //海底炮
+	TianHu     = 8  //天胡
+	DiHu       = 9  //地胡
+	JieHu      = 10 //截胡（规则只允许一人胡）
 
 	// huType
 	PingHu        = 20 //平胡
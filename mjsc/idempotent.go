@@ -0,0 +1,53 @@
+package mjsc
+
+import (
+	"github.com/kevin-chtw/tw_mjsc_svr/metrics"
+	"google.golang.org/protobuf/proto"
+)
+
+// acceptedReq 座位最近一次被接受的请求及其应答
+type acceptedReq struct {
+	seat int32
+	req  proto.Message
+	ack  proto.Message // 处理请求时发给该座位（或广播）的第一条消息（状态切换前），可能为空
+}
+
+// replayDuplicate 网络重发的相同请求（请求id和内容都相同）不再处理，只给该座位重发上次的应答
+func (g *Game) replayDuplicate(seat int32, req proto.Message) bool {
+	last, ok := g.lastReqs[seat]
+	if !ok || requestID(req) == 0 || !proto.Equal(last.req, req) {
+		return false
+	}
+	metrics.RequestRetries.WithLabelValues(string(req.ProtoReflect().Descriptor().Name())).Inc()
+	g.logger().WithField("seat", seat).Infof("duplicate request %d, replay ack", requestID(req))
+	if last.ack != nil {
+		g.replaying = true
+		g.sender.SendMsg(last.ack, seat)
+		g.replaying = false
+	}
+	return true
+}
+
+// handleReq 处理请求，记录被接受的请求和它的应答
+func (g *Game) handleReq(seat int32, req proto.Message) error {
+	accepted := &acceptedReq{seat: seat, req: req}
+	g.capture = accepted
+	err := g.CurState.OnPlayerMsg(seat, req)
+	g.capture = nil
+	if err == nil && requestID(req) != 0 {
+		g.lastReqs[seat] = accepted
+	}
+	return err
+}
+
+// captureAck 记录正在处理的请求发给请求者的第一条消息，target 为发送的目标座位，
+// 不是有效座位时为广播（基类发送的消息无法区分座位，按广播处理）
+func (g *Game) captureAck(msg proto.Message, target int32) {
+	if g.capture == nil || g.capture.ack != nil {
+		return
+	}
+	if g.IsValidSeat(target) && target != g.capture.seat {
+		return
+	}
+	g.capture.ack = proto.Clone(msg)
+}
@@ -0,0 +1,79 @@
+package mjsc
+
+import (
+	"slices"
+
+	"github.com/kevin-chtw/tw_common/gamebase/mahjong"
+	"github.com/kevin-chtw/tw_mjsc_svr/pbmjsc"
+)
+
+// intent 玩家预先登记的等待操作选择，轮到选择时直接生效，不再等待
+type intent struct {
+	autoHu   bool           // 能胡就胡
+	passPon  bool           // 只能碰时一律过
+	ponTiles []mahjong.Tile // 碰指定的牌，生效一次
+}
+
+// intentState 可以立即应用新登记选择（预选或自动操作设置）的等待状态
+type intentState interface {
+	applyIntent(seat int32)
+}
+
+// setIntent 替换玩家的预选，正在等待该玩家选择时立即生效
+func (g *Game) setIntent(seat int32, req *pbmjsc.SCIntentReq) {
+	in := &intent{
+		autoHu:   req.AutoHu,
+		passPon:  req.PassPon,
+		ponTiles: mahjong.Int32Tile(req.PonTiles),
+	}
+	g.play.intents[seat] = in
+	g.sender.sendIntentAck(seat, in)
+	if is, ok := g.CurState.(intentState); ok {
+		is.applyIntent(seat)
+	}
+}
+
+// intentOperate 按预选决定对可选操作的选择，无法确定时返回 false
+func (p *Play) intentOperate(seat int32, operates *mahjong.Operates) (int, bool) {
+	in, ok := p.intents[seat]
+	if !ok || operates == nil || operates.Value == mahjong.OperatePass {
+		return 0, false
+	}
+	if operates.HasOperate(mahjong.OperateHu) {
+		if in.autoHu {
+			return mahjong.OperateHu, true
+		}
+		return 0, false
+	}
+	if !operates.HasOperate(mahjong.OperatePon) || operates.HasOperate(mahjong.OperateKon) {
+		return 0, false
+	}
+	if i := slices.Index(in.ponTiles, p.GetCurTile()); i >= 0 {
+		in.ponTiles = slices.Delete(in.ponTiles, i, i+1)
+		return mahjong.OperatePon, true
+	}
+	if in.passPon {
+		return mahjong.OperatePass, true
+	}
+	return 0, false
+}
+
+func (s *StateWait) applyIntent(seat int32) {
+	if pendingWaitOperates(s.operatesForSeats, s.reqOperateForSeats, seat) == nil {
+		return
+	}
+	if operate, ok := s.game.presetOperate(seat, s.operatesForSeats[seat]); ok {
+		s.setReqOperate(seat, operate)
+		s.tryHandleAction()
+	}
+}
+
+func (s *StateAfterBukon) applyIntent(seat int32) {
+	if pendingWaitOperates(s.operatesForSeats, s.reqOperateForSeats, seat) == nil {
+		return
+	}
+	if operate, ok := s.game.presetOperate(seat, s.operatesForSeats[seat]); ok {
+		s.reqOperateForSeats[seat] = operate
+		s.tryHandleAction()
+	}
+}
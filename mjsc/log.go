@@ -0,0 +1,25 @@
+package mjsc
+
+import (
+	"github.com/kevin-chtw/tw_common/gamebase/game"
+	"github.com/topfreegames/pitaya/v3/pkg/logger"
+	"github.com/topfreegames/pitaya/v3/pkg/logger/interfaces"
+)
+
+// newGameLogger 带桌号、局号的日志，多桌并发时按字段区分
+func newGameLogger(t *game.Table, id int32) interfaces.Logger {
+	matchID, tableID := tableIDs(t)
+	return logger.Log.WithFields(map[string]any{
+		"match": matchID,
+		"table": tableID,
+		"game":  id,
+	})
+}
+
+// logger 当前牌局的日志，附带比赛类型和回合数
+func (g *Game) logger() interfaces.Logger {
+	return g.log.WithFields(map[string]any{
+		"match_type": g.MatchType,
+		"turn":       g.turn,
+	})
+}
@@ -0,0 +1,213 @@
+package mjsc
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kevin-chtw/tw_common/gamebase/game"
+	"github.com/kevin-chtw/tw_common/gamebase/mahjong"
+	"github.com/kevin-chtw/tw_mjsc_svr/collusion"
+	"github.com/kevin-chtw/tw_mjsc_svr/pbmjsc"
+	"github.com/kevin-chtw/tw_proto/game/pbmj"
+)
+
+// match 同一桌连续多局之间共享的数据
+type match struct {
+	gameCount  int32                    // 总局数
+	banker     int32                    // 下局庄家，SeatNull 表示尚未确定
+	wallBanker int32                    // 当前牌墙第一局的庄家
+	players    []*pbmjsc.SCMatchPlayer  // 各座位累计数据
+	games      []*pbmjsc.SCMatchGame    // 每局记录
+	seed       int64                    // 复式赛未指定牌墙种子时随机生成
+	dealTurns  [][]dupTurn              // 当前牌墙每个牌位（相对庄家）的各次得分
+	dupTotals  []int64                  // 各座位累计复式分
+	prefs      map[string]*preference   // 玩家uid -> 自动操作设置，同一场比赛内跨局保留
+	rejects    map[string]*rejectCounts // 玩家uid -> 被拒绝的请求数
+	feed       *spectatorFeed           // 本桌观战推送，观战请求在其他协程访问
+	active     atomic.Int64             // 最近一局开始的时间（UnixNano），其他牌桌清理时读取
+}
+
+// huRecord 一次胡牌（一炮多响算一次）
+type huRecord struct {
+	seats []int32
+	pao   int32
+	zimo  bool
+}
+
+// 按桌保存，第1局时重置，最后一局结束时删除
+var matches sync.Map
+
+// 超过这个时间没有开始新牌局的比赛视为牌桌已解散（中途解散或总局数未知），由 sweepMatches 清除
+const matchIdleTimeout = time.Hour
+
+func loadMatch(t *game.Table, id int32) *match {
+	now := time.Now()
+	sweepMatches(now)
+	if v, ok := matches.Load(t); ok {
+		m := v.(*match)
+		if id > 1 {
+			m.active.Store(now.UnixNano())
+			return m
+		}
+		releaseMatch(t, m)
+	}
+	m := &match{
+		gameCount:  tableGameCount(t),
+		banker:     mahjong.SeatNull,
+		wallBanker: mahjong.SeatNull,
+		seed:       now.UnixNano()%1000000 + 1,
+		prefs:      make(map[string]*preference),
+		rejects:    make(map[string]*rejectCounts),
+		feed:       &spectatorFeed{watchers: make(map[string]bool)},
+	}
+	m.active.Store(now.UnixNano())
+	matches.Store(t, m)
+	return m
+}
+
+// sweepMatches 清除长时间没有新牌局的比赛
+func sweepMatches(now time.Time) {
+	matches.Range(func(k, v any) bool {
+		if now.Sub(time.Unix(0, v.(*match).active.Load())) > matchIdleTimeout {
+			releaseMatch(k.(*game.Table), v.(*match))
+		}
+		return true
+	})
+}
+
+// releaseMatch 删除比赛数据，关闭观战推送
+func releaseMatch(t *game.Table, m *match) {
+	if !matches.CompareAndDelete(t, m) {
+		return
+	}
+	m.feed.close()
+	releaseSeatTables(t)
+}
+
+// selectBanker 复式赛按轮换定庄；其他比赛由上局结果定庄，第一局由基类决定
+func (g *Game) selectBanker() {
+	if banker := g.match.bankerFor(g.isDuplicate(), g.rotation(), g.GetPlayerCount()); banker != mahjong.SeatNull {
+		if !setBanker(g.play.Play, banker) {
+			g.logger().Warnf("play does not support setting banker %d", banker)
+		}
+	}
+}
+
+// bankerFor 本局庄家，SeatNull 表示由基类决定。
+// 复式赛玩家不动、庄家每次轮换顺移一位；非复式赛重放同一副牌墙（dealrepeat>1）时沿用该牌墙第一局的庄家，
+// 评测按座位轮换策略时各策略才能依次坐到每个牌位
+func (m *match) bankerFor(duplicate bool, rotation, count int32) int32 {
+	switch {
+	case duplicate:
+		return rotation % count
+	case rotation > 0:
+		return m.wallBanker
+	default:
+		return m.banker
+	}
+}
+
+// nextBanker 下局庄家：第一个胡牌的玩家坐庄，一炮多响由点炮者坐庄，流局连庄
+func (g *Game) nextBanker() int32 {
+	if len(g.hus) == 0 {
+		return g.play.GetBanker()
+	}
+	first := g.hus[0]
+	if len(first.seats) > 1 && g.IsValidSeat(first.pao) {
+		return first.pao
+	}
+	return first.seats[0]
+}
+
+// recordHu 记录本局胡牌，用于定庄和比赛统计
+func (g *Game) recordHu(huSeats []int32, paoSeat int32, zimo bool) {
+	g.hus = append(g.hus, huRecord{seats: slices.Clone(huSeats), pao: paoSeat, zimo: zimo})
+	if zimo || !g.IsValidSeat(paoSeat) {
+		return
+	}
+	for _, seat := range huSeats {
+		g.play.recordEvent(collusion.KindFeed, paoSeat, seat, g.play.GetPlayData(seat).GetCallData()[g.play.GetCurTile()])
+	}
+}
+
+// onResult 记录本局各座位输赢（结算消息可能按座位多次发送，重复赋值无影响）
+func (g *Game) onResult(acks []*pbmj.MJResultAck) {
+	for _, ack := range acks {
+		for _, result := range ack.PlayerResults {
+			if g.IsValidSeat(result.Seat) {
+				g.results[result.Seat] = result.WinScore
+			}
+		}
+	}
+}
+
+// settleMatch 累计本局数据，最后一局结束后下发比赛总结算
+func (g *Game) settleMatch() {
+	m := g.match
+	count := g.GetPlayerCount()
+	if len(m.players) != int(count) {
+		m.players = make([]*pbmjsc.SCMatchPlayer, count)
+		for seat := range count {
+			m.players[seat] = &pbmjsc.SCMatchPlayer{Seat: seat}
+		}
+	}
+
+	record := &pbmjsc.SCMatchGame{
+		Index:   g.index,
+		Banker:  g.play.GetBanker(),
+		PaoSeat: mahjong.SeatNull,
+		Scores:  slices.Clone(g.results),
+	}
+	for _, hu := range g.hus {
+		record.HuSeats = append(record.HuSeats, hu.seats...)
+		for _, seat := range hu.seats {
+			m.players[seat].HuCount++
+			if hu.zimo {
+				m.players[seat].ZimoCount++
+			}
+		}
+		if !hu.zimo && g.IsValidSeat(hu.pao) {
+			m.players[hu.pao].PaoCount++
+			if record.PaoSeat == mahjong.SeatNull {
+				record.PaoSeat = hu.pao
+			}
+		}
+	}
+	for seat, score := range g.results {
+		m.players[seat].Uid = g.GetPlayer(int32(seat)).Uid
+		m.players[seat].TotalScore += score
+	}
+	m.games = append(m.games, record)
+	m.banker = g.nextBanker()
+	if g.rotation() == 0 {
+		m.wallBanker = g.play.GetBanker()
+	}
+
+	if m.gameCount > 0 && g.index >= m.gameCount {
+		g.sender.sendMatchResultAck(m.ranking(), m.games)
+		releaseMatch(g.table, m)
+	}
+}
+
+// ranking 按累计得分排名，同分同名次
+func (m *match) ranking() []*pbmjsc.SCMatchPlayer {
+	players := slices.Clone(m.players)
+	slices.SortStableFunc(players, func(a, b *pbmjsc.SCMatchPlayer) int {
+		switch {
+		case a.TotalScore > b.TotalScore:
+			return -1
+		case a.TotalScore < b.TotalScore:
+			return 1
+		}
+		return 0
+	})
+	for i, p := range players {
+		p.Rank = int32(i + 1)
+		if i > 0 && p.TotalScore == players[i-1].TotalScore {
+			p.Rank = players[i-1].Rank
+		}
+	}
+	return players
+}
@@ -0,0 +1,94 @@
+package mjsc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kevin-chtw/tw_common/gamebase/game"
+	"github.com/kevin-chtw/tw_common/gamebase/mahjong"
+)
+
+// TestBankerPositions 同一副牌墙轮换完后，每个策略在每个牌位（相对庄家）各打一局
+func TestBankerPositions(t *testing.T) {
+	const count = 4
+	tests := []struct {
+		name      string
+		duplicate bool
+		strategy  func(seat, rotation int32) int32 // 第 rotation 次轮换时坐在 seat 的策略
+	}{
+		// 复式赛玩家不动，庄家轮换
+		{"duplicate", true, func(seat, _ int32) int32 { return seat }},
+		// 评测重放牌墙（dealrepeat=4），策略每局顺移一个座位，庄家不动
+		{"arena", false, func(seat, r int32) int32 { return (seat + r) % count }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &match{banker: 1, wallBanker: mahjong.SeatNull}
+			played := make(map[[2]int32]int) // {策略, 牌位} -> 局数
+			for r := range int32(count) {
+				banker := m.bankerFor(tt.duplicate, r, count)
+				if banker == mahjong.SeatNull {
+					banker = 2 // 第一局由基类定庄
+				}
+				if r == 0 {
+					m.wallBanker = banker
+				}
+				m.banker = (r + 3) % count // 上局胡牌者，重放牌墙时不应影响定庄
+				for seat := range int32(count) {
+					pos := (seat - banker + count) % count
+					played[[2]int32{tt.strategy(seat, r), pos}]++
+				}
+			}
+			for k := range int32(count) {
+				for pos := range int32(count) {
+					if n := played[[2]int32{k, pos}]; n != 1 {
+						t.Errorf("strategy %d played position %d %d times, want 1", k, pos, n)
+					}
+				}
+			}
+		})
+	}
+}
+
+// TestBankerFromWinner 不重放牌墙时（包括设置了种子但 dealrepeat=1 的训练桌）由上局结果定庄
+func TestBankerFromWinner(t *testing.T) {
+	m := &match{banker: mahjong.SeatNull, wallBanker: mahjong.SeatNull}
+	if got := m.bankerFor(false, 0, 4); got != mahjong.SeatNull {
+		t.Errorf("first game banker = %d, want SeatNull", got)
+	}
+	for _, winner := range []int32{2, 3, 0} {
+		m.banker = winner
+		m.wallBanker = 1
+		if got := m.bankerFor(false, 0, 4); got != winner {
+			t.Errorf("banker = %d, want winner %d", got, winner)
+		}
+	}
+}
+
+// TestSweepMatches 中途解散的牌桌不会再结束最后一局，超时后清除比赛数据和观战登记
+func TestSweepMatches(t *testing.T) {
+	now := time.Now()
+	idle, live := &game.Table{}, &game.Table{}
+	for table, active := range map[*game.Table]time.Time{idle: now.Add(-matchIdleTimeout - time.Second), live: now} {
+		m := &match{feed: &spectatorFeed{watchers: make(map[string]bool)}}
+		m.active.Store(active.UnixNano())
+		matches.Store(table, m)
+		defer matches.Delete(table)
+	}
+	seatTables.Store("idle", seatTable{table: idle})
+	defer seatTables.Delete("idle")
+	feed := loadFeed(idle)
+	sweepMatches(now)
+	if _, ok := matches.Load(idle); ok {
+		t.Error("idle match not swept")
+	}
+	if _, ok := seatTables.Load("idle"); ok {
+		t.Error("seat of idle table not released")
+	}
+	if feed.watch("spectator", false) {
+		t.Error("watching a swept table")
+	}
+	if _, ok := matches.Load(live); !ok {
+		t.Error("live match swept")
+	}
+}
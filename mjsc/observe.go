@@ -0,0 +1,46 @@
+package mjsc
+
+import (
+	"github.com/kevin-chtw/tw_mjsc_svr/metrics"
+	"github.com/kevin-chtw/tw_proto/game/pbmj"
+	"google.golang.org/protobuf/proto"
+)
+
+// observeMsg 根据下发的消息统计胡牌、托管和账目平衡
+func (g *Game) observeMsg(msg proto.Message) {
+	if msg == g.observed {
+		return
+	}
+	g.observed = msg
+
+	switch ack := msg.(type) {
+	case *pbmj.MJHuAck:
+		for _, h := range ack.HuData {
+			metrics.ObserveHu(h.HuTypes, h.Multi)
+		}
+	case *pbmj.MJTrustAck:
+		if ack.GetTrust() {
+			metrics.TrustActivations.Inc()
+		}
+	case *pbmj.MJScoreChangeAck:
+		if sum(ack.GetScores()) != 0 {
+			metrics.LedgerViolations.WithLabelValues(metrics.LedgerScoreChange).Inc()
+		}
+	case *pbmj.MJResultAck:
+		total := int64(0)
+		for _, result := range ack.PlayerResults {
+			total += result.WinScore
+		}
+		if total != 0 {
+			metrics.LedgerViolations.WithLabelValues(metrics.LedgerResult).Inc()
+		}
+	}
+}
+
+func sum(values []int64) int64 {
+	total := int64(0)
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
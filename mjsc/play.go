@@ -1,17 +1,43 @@
 package mjsc
 
-import "github.com/kevin-chtw/tw_common/gamebase/mahjong"
+import (
+	"slices"
+
+	"github.com/kevin-chtw/tw_common/gamebase/mahjong"
+	"github.com/kevin-chtw/tw_mjsc_svr/collusion"
+)
+
+const (
+	// RuleGuoHu
+	GuoHuNone     = 0 //不限制
+	GuoHuSameTile = 1 //摸牌前不能胡同一张牌
+	GuoHuLowerFan = 2 //摸牌前不能胡番数不高于放弃的胡
+)
 
 type Play struct {
 	*mahjong.Play
 	dealer    *mahjong.Dealer
 	queColors map[int32]mahjong.EColor
+	passHus   map[int32]*passHu        // 各座位放弃的胡，到自己下次摸牌（或碰牌）前有效
+	passPons  map[int32][]mahjong.Tile // 各座位放弃碰的牌，有效期同上
+	events    []collusion.Event        // 座位间的点炮、弃胡、碰牌记录，局后做防串通分析
+	intents   map[int32]*intent        // 各座位预先登记的等待操作选择
+	jieHu     bool                     // 本次点炮截掉了其他能胡的玩家，胡牌类型计截胡
+}
+
+// passHu 放弃胡牌的记录
+type passHu struct {
+	tiles []mahjong.Tile
+	multi int64 // 放弃的最大番数
 }
 
 func NewPlay(game *Game) *Play {
 	p := &Play{
 		dealer:    mahjong.NewDealer(game.Game),
 		queColors: make(map[int32]mahjong.EColor),
+		passHus:   make(map[int32]*passHu),
+		passPons:  make(map[int32][]mahjong.Tile),
+		intents:   make(map[int32]*intent),
 	}
 	p.Play = mahjong.NewPlay(p, game.Game, p.dealer)
 	p.PlayConf = &mahjong.PlayConf{
@@ -43,15 +69,93 @@ func (p *Play) queRecommand(seat int32) mahjong.EColor {
 	return bestColor
 }
 
+// discard 出牌，TileNull 为超时或托管自动出牌，有缺门牌时先打缺门
 func (p *Play) discard(tile mahjong.Tile) bool {
-	seat := p.GetCurSeat()
-	queTile := p.getQueTile(seat)
-	if queTile != mahjong.TileNull && tile.Color() != queTile.Color() {
-		tile = queTile
+	if tile == mahjong.TileNull {
+		tile = p.getQueTile(p.GetCurSeat())
 	}
 	return p.Play.Discard(tile)
 }
 
+// checkDiscard 校验玩家请求打出的牌
+func (p *Play) checkDiscard(seat int32, tile mahjong.Tile) error {
+	if !slices.Contains(p.GetPlayData(seat).GetHandTiles(), tile) {
+		return reject(RejectTileNotInHand, "tile %d", tile)
+	}
+	if queTile := p.getQueTile(seat); queTile != mahjong.TileNull && tile.Color() != queTile.Color() {
+		return reject(RejectVoidSuit, "tile %d, must discard color %d first", tile, queTile.Color())
+	}
+	return nil
+}
+
+// declineHu 记录玩家放弃了当前这张牌的胡
+func (p *Play) declineHu(seat int32) {
+	tile := p.GetCurTile()
+	ph, ok := p.passHus[seat]
+	if !ok {
+		ph = &passHu{}
+		p.passHus[seat] = ph
+	}
+	ph.tiles = append(ph.tiles, tile)
+	ph.multi = max(ph.multi, p.GetPlayData(seat).GetCallData()[tile])
+}
+
+// recordDeclines 等待操作处理时，记录可以胡却选择了其他操作、可以碰却选择过的座位。
+// 过手胡、过碰限制对所有放弃都生效，防串通统计只计玩家主动请求（playerReqs）的放弃
+func (p *Play) recordDeclines(operates []*mahjong.Operates, reqs map[int32]int, playerReqs map[int32]bool) {
+	for seat, ops := range operates {
+		operate, ok := reqs[int32(seat)]
+		if ops == nil || !ok {
+			continue
+		}
+		chosen := playerReqs[int32(seat)]
+		if ops.HasOperate(mahjong.OperateHu) && operate != mahjong.OperateHu {
+			p.declineHu(int32(seat))
+			if chosen {
+				p.recordEvent(collusion.KindDecline, p.GetCurSeat(), int32(seat), p.GetPlayData(int32(seat)).GetCallData()[p.GetCurTile()])
+			}
+		}
+		if ops.HasOperate(mahjong.OperatePon) && operate == mahjong.OperatePass {
+			p.passPons[int32(seat)] = append(p.passPons[int32(seat)], p.GetCurTile())
+			if chosen {
+				p.recordEvent(collusion.KindPassPon, p.GetCurSeat(), int32(seat), 0)
+			}
+		}
+	}
+}
+
+// recordEvent 记录 to 对 from 打出（或杠出）的牌的一次操作
+func (p *Play) recordEvent(kind int, from, to int32, fan int64) {
+	p.events = append(p.events, collusion.Event{Kind: kind, From: from, To: to, Fan: fan})
+}
+
+// clearDeclines 轮到自己（摸牌或碰牌）后解除过手胡、过碰限制
+func (p *Play) clearDeclines(seat int32) {
+	delete(p.passHus, seat)
+	delete(p.passPons, seat)
+}
+
+// isPonLocked 过碰限制下不能碰这张牌
+func (p *Play) isPonLocked(seat int32, tile mahjong.Tile) bool {
+	return p.GetRule().GetValue(RuleGuoPon) != 0 && slices.Contains(p.passPons[seat], tile)
+}
+
+// isHuLocked 过手胡限制下不能胡这张牌
+func (p *Play) isHuLocked(seat int32, tile mahjong.Tile) bool {
+	ph, ok := p.passHus[seat]
+	if !ok {
+		return false
+	}
+	switch p.GetRule().GetValue(RuleGuoHu) {
+	case GuoHuSameTile:
+		return slices.Contains(ph.tiles, tile)
+	case GuoHuLowerFan:
+		return p.GetPlayData(seat).GetCallData()[tile] <= ph.multi
+	default:
+		return false
+	}
+}
+
 func (p *Play) getQueTile(seat int32) mahjong.Tile {
 	color := p.queColors[seat]
 	tiles := p.GetPlayData(seat).GetHandTiles()
@@ -103,6 +207,9 @@ func (p *Play) selfHuTypes() []int32 {
 func (p *Play) paoHuTypes(seat int32) []int32 {
 	types := make([]int32, 0)
 	types = append(types, PaoHu)
+	if p.jieHu {
+		types = append(types, JieHu)
+	}
 	if p.IsAfterKon() {
 		if p.GetCurSeat() == seat {
 			types = append(types, QiangKonHu)
@@ -116,6 +223,33 @@ func (p *Play) paoHuTypes(seat int32) []int32 {
 	return types
 }
 
+// markJieHu 截胡规则下，胡牌者之后还有人能胡时才计截胡
+func (p *Play) markJieHu(huSeats []int32, operates []*mahjong.Operates) {
+	p.jieHu = false
+	if p.GetRule().GetValue(RuleJieHu) == 0 || len(huSeats) != 1 {
+		return
+	}
+	canHu := make([]bool, len(operates))
+	for seat, ops := range operates {
+		canHu[seat] = ops != nil && ops.HasOperate(mahjong.OperateHu)
+	}
+	p.jieHu = cutHu(p.GetCurSeat(), huSeats[0], canHu)
+}
+
+// cutHu 从点炮者下家开始，winner 之后是否还有座位能胡
+func cutHu(paoSeat, winner int32, canHu []bool) bool {
+	count := int32(len(canHu))
+	passed := false
+	for i := int32(1); i < count; i++ {
+		seat := mahjong.GetNextSeat(paoSeat, i, count)
+		if passed && canHu[seat] {
+			return true
+		}
+		passed = passed || seat == winner
+	}
+	return false
+}
+
 func (p *Play) showCount(tile mahjong.Tile) int {
 	count := 0
 	playerCount := p.GetPlayerCount()
@@ -0,0 +1,26 @@
+package mjsc
+
+import "testing"
+
+func TestCutHu(t *testing.T) {
+	tests := []struct {
+		name   string
+		pao    int32
+		winner int32
+		canHu  []bool
+		want   bool
+	}{
+		{"only winner", 0, 1, []bool{false, true, false, false}, false},
+		{"later seat can hu", 0, 1, []bool{false, true, false, true}, true},
+		{"earlier seat passed", 0, 2, []bool{false, true, true, false}, false},
+		{"wrap around", 2, 3, []bool{false, true, false, true}, true},
+		{"winner is last", 2, 1, []bool{true, true, false, true}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cutHu(tt.pao, tt.winner, tt.canHu); got != tt.want {
+				t.Errorf("cutHu(%d, %d, %v) = %v, want %v", tt.pao, tt.winner, tt.canHu, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package mjsc
+
+import (
+	"github.com/kevin-chtw/tw_common/gamebase/mahjong"
+	"github.com/kevin-chtw/tw_mjsc_svr/pbmjsc"
+)
+
+// preference 玩家的自动操作设置，同一场比赛内跨局保留，不进入托管也能跳过简单的选择
+type preference struct {
+	autoHu      bool // 能胡就胡（点炮、自摸、抢杠）
+	autoPass    bool // 不能胡时碰、杠一律过
+	autoDiscard bool // 听牌后摸到不能胡、不能杠的牌直接打出
+}
+
+// loadPreference 玩家的自动操作设置保存在本桌的比赛数据中，随比赛一起释放
+func (g *Game) loadPreference(seat int32) *preference {
+	return g.match.prefs[g.GetPlayer(seat).Uid]
+}
+
+// setPreference 替换玩家的自动操作设置，正在等待该玩家选择时立即生效
+func (g *Game) setPreference(seat int32, req *pbmjsc.SCPreferenceReq) {
+	pref := &preference{
+		autoHu:      req.AutoHu,
+		autoPass:    req.AutoPass,
+		autoDiscard: req.AutoDiscard,
+	}
+	g.match.prefs[g.GetPlayer(seat).Uid] = pref
+	g.sender.sendPreferenceAck(seat, pref)
+	if is, ok := g.CurState.(intentState); ok {
+		is.applyIntent(seat)
+	}
+}
+
+// presetOperate 按预选和自动操作设置决定等待操作，预选优先
+func (g *Game) presetOperate(seat int32, operates *mahjong.Operates) (int, bool) {
+	if operate, ok := g.play.intentOperate(seat, operates); ok {
+		return operate, true
+	}
+	pref := g.loadPreference(seat)
+	if pref == nil || operates == nil || operates.Value == mahjong.OperatePass {
+		return 0, false
+	}
+	if operates.HasOperate(mahjong.OperateHu) {
+		return mahjong.OperateHu, pref.autoHu
+	}
+	return mahjong.OperatePass, pref.autoPass
+}
+
+// autoDiscard 出牌阶段按自动操作设置自摸或打出摸到的牌，drawn 为本次摸到的牌
+func (s *StateDiscard) autoDiscard(drawn mahjong.Tile) bool {
+	seat := s.game.play.GetCurSeat()
+	pref := s.game.loadPreference(seat)
+	if pref == nil {
+		return false
+	}
+	if s.operates.HasOperate(mahjong.OperateHu) {
+		if pref.autoHu {
+			s.hu(drawn)
+		}
+		return pref.autoHu
+	}
+	if !autoDiscardReady(pref, drawn, s.operates.HasOperate(mahjong.OperateKon),
+		len(s.game.play.GetPlayData(seat).GetCallData())) {
+		return false
+	}
+	s.discard(drawn)
+	return true
+}
+
+// autoDiscardReady 是否直接打出摸到的牌。callCount 是摸牌前手牌的叫牌数，
+// 摸牌后还没有重新计算；打出摸到的牌后手牌回到摸牌前，所以摸牌前听牌即可打出。
+// 碰牌后没有摸牌（drawn 为空），有杠时需要玩家选择
+func autoDiscardReady(pref *preference, drawn mahjong.Tile, hasKon bool, callCount int) bool {
+	return pref.autoDiscard && drawn != mahjong.TileNull && !hasKon && callCount > 0
+}
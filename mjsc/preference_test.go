@@ -0,0 +1,33 @@
+package mjsc
+
+import (
+	"testing"
+
+	"github.com/kevin-chtw/tw_common/gamebase/mahjong"
+)
+
+func TestAutoDiscardReady(t *testing.T) {
+	drawn := mahjong.MakeTile(mahjong.ColorDot, 4)
+	on := &preference{autoDiscard: true}
+	tests := []struct {
+		name      string
+		pref      *preference
+		drawn     mahjong.Tile
+		hasKon    bool
+		callCount int // 摸牌前手牌的叫牌数
+		want      bool
+	}{
+		{"ready", on, drawn, false, 2, true},
+		{"not ready before draw", on, drawn, false, 0, false},
+		{"disabled", &preference{autoHu: true}, drawn, false, 2, false},
+		{"after pon", on, mahjong.TileNull, false, 2, false},
+		{"can kon", on, drawn, true, 2, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := autoDiscardReady(tt.pref, tt.drawn, tt.hasKon, tt.callCount); got != tt.want {
+				t.Errorf("autoDiscardReady = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package mjsc
+
+import (
+	"fmt"
+
+	"github.com/kevin-chtw/tw_mjsc_svr/metrics"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// 请求被拒绝的原因，通过 SCErrorAck 下发给客户端
+	RejectStaleRequest      = 1 //请求id过期或不匹配
+	RejectWrongSeat         = 2 //不是该座位的请求
+	RejectTileNotInHand     = 3 //手牌中没有该牌
+	RejectVoidSuit          = 4 //还有缺门牌时必须先打缺门
+	RejectOperateNotOffered = 5 //未提供的操作
+	RejectAlreadySubmitted  = 6 //本阶段已经提交过
+	RejectInvalidColor      = 7 //定缺花色无效
+	RejectInvalidTiles      = 8 //换三张的牌无效
+	RejectInvalidState      = 9 //当前阶段不接受该请求
+)
+
+var rejectNames = map[int32]string{
+	RejectStaleRequest:      "stale_request",
+	RejectWrongSeat:         "wrong_seat",
+	RejectTileNotInHand:     "tile_not_in_hand",
+	RejectVoidSuit:          "void_suit",
+	RejectOperateNotOffered: "operate_not_offered",
+	RejectAlreadySubmitted:  "already_submitted",
+	RejectInvalidColor:      "invalid_color",
+	RejectInvalidTiles:      "invalid_tiles",
+	RejectInvalidState:      "invalid_state",
+}
+
+// 每个玩家被拒绝的请求累计到这个数量的倍数时告警
+const rejectAlertEvery = 20
+
+// rejectCounts 玩家在本场比赛内被拒绝的请求数，跨局累计，用于发现异常客户端
+type rejectCounts struct {
+	total  int
+	counts map[int32]int
+}
+
+// RejectError 带原因的请求拒绝
+type RejectError struct {
+	Code   int32
+	Reason string
+}
+
+func (e *RejectError) Error() string {
+	return fmt.Sprintf("%s: %s", rejectNames[e.Code], e.Reason)
+}
+
+func reject(code int32, format string, args ...any) error {
+	return &RejectError{Code: code, Reason: fmt.Sprintf(format, args...)}
+}
+
+// countReject 计数并返回玩家本场比赛的累计
+func (m *match) countReject(uid string, code int32) *rejectCounts {
+	rc, ok := m.rejects[uid]
+	if !ok {
+		rc = &rejectCounts{counts: make(map[int32]int)}
+		m.rejects[uid] = rc
+	}
+	rc.counts[code]++
+	rc.total++
+	return rc
+}
+
+// onReject 向请求的玩家下发拒绝原因并计数
+func (g *Game) onReject(seat int32, req proto.Message, rej *RejectError) {
+	uid := g.GetPlayer(seat).Uid
+	rc := g.match.countReject(uid, rej.Code)
+	metrics.Rejections.WithLabelValues(rejectNames[rej.Code]).Inc()
+
+	log := g.logger().WithFields(map[string]any{
+		"seat":   seat,
+		"uid":    uid,
+		"code":   rejectNames[rej.Code],
+		"total":  rc.total,
+		"reason": rej.Reason,
+	})
+	if rc.total%rejectAlertEvery == 0 {
+		log.WithField("counts", rc.counts).Warnf("player rejected %d requests", rc.total)
+	} else {
+		log.Infof("reject %T", req)
+	}
+	g.sender.sendErrorAck(seat, req, rej)
+}
+
+// requestID 请求中的请求id，没有时为0
+func requestID(req proto.Message) int32 {
+	if r, ok := req.(interface{ GetRequestid() int32 }); ok {
+		return r.GetRequestid()
+	}
+	return 0
+}
@@ -3,6 +3,8 @@ package mjsc
 import (
 	"github.com/kevin-chtw/tw_common/gamebase/game"
 	"github.com/kevin-chtw/tw_common/gamebase/mahjong"
+	"github.com/kevin-chtw/tw_mjsc_svr/pbmjsc"
+	"github.com/kevin-chtw/tw_proto/game/pbmj"
 	"github.com/kevin-chtw/tw_proto/game/pbsc"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/anypb"
@@ -10,11 +12,13 @@ import (
 
 type Sender struct {
 	*mahjong.Sender
-	game *Game
+	game    *Game
+	results []*pbmj.MJResultAck // sendResult 期间打包的结算消息
+	target  int32               // sendMsg 正在发送的目标座位，基类发送时为 SeatNull
 }
 
 func NewSender(game *Game) *Sender {
-	s := &Sender{game: game}
+	s := &Sender{game: game, target: mahjong.SeatNull}
 	s.Sender = mahjong.NewSender(game.Game, game.play.Play, s)
 	return s
 }
@@ -25,21 +29,49 @@ func (m *Sender) PackMsg(msg proto.Message) (proto.Message, error) {
 		return nil, err
 	}
 	ack := &pbsc.SCAck{Ack: data}
+	if m.game.replaying {
+		return ack, nil
+	}
+	if result, ok := msg.(*pbmj.MJResultAck); ok {
+		m.results = append(m.results, result)
+	}
+	m.game.captureAck(msg, m.target)
+	m.game.observeMsg(msg)
+	m.game.publishSpectators(msg)
+	m.game.publishDebug()
 	return ack, nil
 }
 
-func (s *Sender) sendSwapTilesAck() {
+// sendMsg 发送并记录目标座位，处理请求时据此记下发给请求者的应答
+func (s *Sender) sendMsg(msg proto.Message, seat int32) {
+	s.target = seat
+	s.SendMsg(msg, seat)
+	s.target = mahjong.SeatNull
+}
+
+// sendResult 发送结算并返回实际发出的结算消息（可能按座位多次发送）
+func (s *Sender) sendResult(liuju bool) []*pbmj.MJResultAck {
+	s.results = nil
+	s.SendResult(liuju)
+	results := s.results
+	s.results = nil
+	return results
+}
+
+// sendSwapTilesAck 请求换三张，seat 为 game.SeatAll 时发给所有人，断线重连时单独补发
+func (s *Sender) sendSwapTilesAck(seat int32) {
 	ack := &pbsc.SCSwapTilesAck{
-		Requestid: s.GetRequestID(game.SeatAll),
+		Requestid: s.GetRequestID(seat),
 	}
-	s.SendMsg(ack, game.SeatAll)
+	s.sendMsg(ack, seat)
 }
 
-func (s *Sender) sendDingQueAck() {
+// sendDingQueAck 请求定缺，seat 用法同 sendSwapTilesAck
+func (s *Sender) sendDingQueAck(seat int32) {
 	ack := &pbsc.SCDingQueAck{
-		Requestid: s.GetRequestID(game.SeatAll),
+		Requestid: s.GetRequestID(seat),
 	}
-	s.SendMsg(ack, game.SeatAll)
+	s.sendMsg(ack, seat)
 }
 
 func (s *Sender) sendSwapFinishAck(seat int32, tiles []int32) {
@@ -47,11 +79,11 @@ func (s *Sender) sendSwapFinishAck(seat int32, tiles []int32) {
 		Seat:  seat,
 		Tiles: tiles,
 	}
-	s.SendMsg(ack, seat)
+	s.sendMsg(ack, seat)
 	ack.Tiles = nil
 	for i := int32(0); i < s.game.GetPlayerCount(); i++ {
 		if i != seat {
-			s.SendMsg(ack, i)
+			s.sendMsg(ack, i)
 		}
 	}
 }
@@ -61,7 +93,7 @@ func (s *Sender) sendSwapTilesResultAck(swapType int32, swaps []*pbsc.SCSwapTile
 		SwapType:  swapType,
 		SwapTiles: swaps,
 	}
-	s.SendMsg(ack, game.SeatAll)
+	s.sendMsg(ack, game.SeatAll)
 }
 
 func (s *Sender) sendDingQueFinishAck(seat int32, color int32) {
@@ -69,11 +101,11 @@ func (s *Sender) sendDingQueFinishAck(seat int32, color int32) {
 		Seat:  seat,
 		Color: color,
 	}
-	s.SendMsg(ack, seat)
+	s.sendMsg(ack, seat)
 	ack.Color = int32(mahjong.ColorUndefined)
 	for i := int32(0); i < s.game.GetPlayerCount(); i++ {
 		if i != seat {
-			s.SendMsg(ack, i)
+			s.sendMsg(ack, i)
 		}
 	}
 }
@@ -85,5 +117,55 @@ func (s *Sender) sendDingQueResultAck(queColors map[int32]mahjong.EColor) {
 	for i := 0; i < len(queColors); i++ {
 		ack.Colors = append(ack.Colors, int32(queColors[int32(i)]))
 	}
-	s.SendMsg(ack, game.SeatAll)
+	s.sendMsg(ack, game.SeatAll)
+}
+
+// sendIntentAck 确认玩家当前的预选
+func (s *Sender) sendIntentAck(seat int32, in *intent) {
+	ack := &pbmjsc.SCIntentAck{
+		AutoHu:   in.autoHu,
+		PassPon:  in.passPon,
+		PonTiles: mahjong.TilesInt32(in.ponTiles),
+	}
+	s.sendMsg(ack, seat)
+}
+
+// sendPreferenceAck 确认玩家当前的自动操作设置
+func (s *Sender) sendPreferenceAck(seat int32, pref *preference) {
+	ack := &pbmjsc.SCPreferenceAck{
+		AutoHu:      pref.autoHu,
+		AutoPass:    pref.autoPass,
+		AutoDiscard: pref.autoDiscard,
+	}
+	s.sendMsg(ack, seat)
+}
+
+// sendErrorAck 请求被拒绝时只下发给请求的玩家
+func (s *Sender) sendErrorAck(seat int32, req proto.Message, rej *RejectError) {
+	ack := &pbmjsc.SCErrorAck{
+		Code:      rej.Code,
+		Reason:    rej.Reason,
+		Requestid: requestID(req),
+		ReqType:   string(req.ProtoReflect().Descriptor().FullName()),
+	}
+	s.sendMsg(ack, seat)
+}
+
+// sendDuplicateResultAck 复式赛一副牌墙轮换完毕，下发本副牌墙复式分及累计复式分
+func (s *Sender) sendDuplicateResultAck(deal int32, scores []int64, totals []int64) {
+	ack := &pbmjsc.SCDuplicateResultAck{
+		Deal:   deal,
+		Scores: scores,
+		Totals: totals,
+	}
+	s.sendMsg(ack, game.SeatAll)
+}
+
+// sendMatchResultAck 比赛全部局数结束，下发排名和每局记录
+func (s *Sender) sendMatchResultAck(players []*pbmjsc.SCMatchPlayer, games []*pbmjsc.SCMatchGame) {
+	ack := &pbmjsc.SCMatchResultAck{
+		Players: players,
+		Games:   games,
+	}
+	s.sendMsg(ack, game.SeatAll)
 }
@@ -21,7 +21,7 @@ func NewService() mahjong.IService {
 	s := &service{
 		tiles:        make(map[mahjong.Tile]int),
 		tiles2Men:    make(map[mahjong.Tile]int),
-		defaultRules: [RuleEnd]int{10, 8, 0, 1, 10, 0, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 0, 0, 1, 0, 1, 1},
+		defaultRules: [RuleEnd]int{10, 8, 0, 1, 10, 0, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 0, 0, 1, 0, 1, 1, 0, 1, 0, 0, 0, 0},
 		huCore:       mahjong.NewHuCore(14),
 		fdRules:      make(map[string]int32),
 	}
@@ -77,6 +77,12 @@ func (s *service) initFdRules() {
 	s.fdRules["chagua"] = RuleChaGua           //擦挂
 	s.fdRules["diankhsdp"] = RuleDianKHSDP     //点杠花算点炮
 	s.fdRules["juezhang"] = RuleJueZhang       //绝张
+	s.fdRules["seed"] = RuleWallSeed           //牌墙种子
+	s.fdRules["dealrepeat"] = RuleDealRepeat   //同一牌墙连续局数
+	s.fdRules["jiehu"] = RuleJieHu             //截胡
+	s.fdRules["zhuanyufen"] = RuleZhuanYuFen   //呼叫转移分配方式
+	s.fdRules["guohu"] = RuleGuoHu             //过手胡
+	s.fdRules["guopon"] = RuleGuoPon           //过碰
 }
 
 func (s *service) GetFdRules() map[string]int32 {
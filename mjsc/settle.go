@@ -0,0 +1,86 @@
+package mjsc
+
+import (
+	"github.com/kevin-chtw/tw_common/gamebase/mahjong"
+)
+
+const (
+	// RuleZhuanYuFen
+	ZhuanYuEven  = 0 //平分，余数给第一个胡牌者
+	ZhuanYuFull  = 1 //每个胡牌者都得全额，超出杠分的部分由点炮者自付
+	ZhuanYuFirst = 2 //全部给第一个胡牌者
+)
+
+// konScore 最近一次杠的得分，点炮时用于呼叫转移
+type konScore struct {
+	seat  int32 // 杠牌玩家
+	win   int64 // 杠牌玩家所得
+	index int   // 在杠牌玩家杠分记录（GetKonScores）中的位置
+}
+
+// recordKon 记录杠分，杠牌玩家本轮点炮时转移，须在 CalcKon 之后调用
+func (g *Game) recordKon(seat int32, scores []int64) {
+	g.lastKon = &konScore{seat: seat, win: scores[seat], index: len(g.scorelator.GetKonScores(seat)) - 1}
+}
+
+// konMoved 杠分记录是否已呼叫转移
+func (g *Game) konMoved(seat int32, index int) bool {
+	for _, kon := range g.movedKons {
+		if kon.seat == seat && kon.index == index {
+			return true
+		}
+	}
+	return false
+}
+
+// clearKon 杠牌玩家本轮结束（出牌无人胡）后不再转移
+func (g *Game) clearKon() {
+	g.lastKon = nil
+}
+
+// settlePaoHu 点炮（含抢杠）结算：先呼叫转移，再算胡牌分
+func (g *Game) settlePaoHu(huSeats []int32) {
+	paoSeat := g.play.GetCurSeat()
+	g.zhuanYu(paoSeat, huSeats)
+
+	multiples := g.play.PaoHu(huSeats)
+	scores := g.scorelator.CalcMulti(mahjong.SeatNull, mahjong.ScoreReasonHu, multiples)
+	g.sender.SendScoreChangeAck(mahjong.ScoreReasonHu, scores, g.play.GetCurTile(), paoSeat, huSeats)
+}
+
+// zhuanYu 呼叫转移：点炮者刚杠过牌，杠分转给胡牌者
+func (g *Game) zhuanYu(paoSeat int32, huSeats []int32) {
+	kon := g.lastKon
+	g.clearKon()
+	if g.GetRule().GetValue(RuleZhuanYu) == 0 || kon == nil || kon.seat != paoSeat || kon.win <= 0 {
+		return
+	}
+	// 杠分已转移，流局时不再退杠
+	g.movedKons = append(g.movedKons, *kon)
+	split := zhuanYuScores(g.GetPlayerCount(), paoSeat, huSeats, kon.win, g.GetRule().GetValue(RuleZhuanYuFen))
+	final := g.scorelator.CalcScores(mahjong.SeatNull, mahjong.ScoreReasonZhuanYu, split)
+	g.sender.SendScoreChangeAck(mahjong.ScoreReasonZhuanYu, final, g.play.GetCurTile(), paoSeat, huSeats)
+}
+
+// zhuanYuScores 按分配方式计算呼叫转移各座位得分，ZhuanYuFull 时点炮者共付 win*胡牌人数
+func zhuanYuScores(count int32, paoSeat int32, huSeats []int32, win int64, split int) []int64 {
+	scores := make([]int64, count)
+	switch split {
+	case ZhuanYuFull:
+		for _, seat := range huSeats {
+			scores[seat] = win
+		}
+		scores[paoSeat] = -win * int64(len(huSeats))
+	case ZhuanYuFirst:
+		scores[huSeats[0]] = win
+		scores[paoSeat] = -win
+	default:
+		avg := win / int64(len(huSeats))
+		for _, seat := range huSeats {
+			scores[seat] = avg
+		}
+		scores[huSeats[0]] += win % int64(len(huSeats))
+		scores[paoSeat] = -win
+	}
+	return scores
+}
@@ -0,0 +1,69 @@
+package mjsc
+
+import (
+	"slices"
+	"testing"
+)
+
+// 点炮者为0号座位，胡牌者按点炮者下家开始排列；杠分：补杠3、直杠2（查瓜4）、暗杠6
+func TestZhuanYuScores(t *testing.T) {
+	tests := []struct {
+		name    string
+		split   int
+		win     int64
+		huSeats []int32
+		want    []int64
+	}{
+		{"even bu 1 hu", ZhuanYuEven, 3, []int32{1}, []int64{-3, 3, 0, 0}},
+		{"even bu 2 hu", ZhuanYuEven, 3, []int32{1, 3}, []int64{-3, 2, 0, 1}},
+		{"even bu 3 hu", ZhuanYuEven, 3, []int32{1, 2, 3}, []int64{-3, 1, 1, 1}},
+		{"even zhi 1 hu", ZhuanYuEven, 2, []int32{1}, []int64{-2, 2, 0, 0}},
+		{"even zhi 2 hu", ZhuanYuEven, 2, []int32{1, 3}, []int64{-2, 1, 0, 1}},
+		{"even zhi 3 hu", ZhuanYuEven, 2, []int32{1, 2, 3}, []int64{-2, 2, 0, 0}},
+		{"even zhi chagua 1 hu", ZhuanYuEven, 4, []int32{1}, []int64{-4, 4, 0, 0}},
+		{"even zhi chagua 2 hu", ZhuanYuEven, 4, []int32{1, 3}, []int64{-4, 2, 0, 2}},
+		{"even zhi chagua 3 hu", ZhuanYuEven, 4, []int32{1, 2, 3}, []int64{-4, 2, 1, 1}},
+		{"even an 1 hu", ZhuanYuEven, 6, []int32{1}, []int64{-6, 6, 0, 0}},
+		{"even an 2 hu", ZhuanYuEven, 6, []int32{1, 3}, []int64{-6, 3, 0, 3}},
+		{"even an 3 hu", ZhuanYuEven, 6, []int32{1, 2, 3}, []int64{-6, 2, 2, 2}},
+		{"full bu 1 hu", ZhuanYuFull, 3, []int32{1}, []int64{-3, 3, 0, 0}},
+		{"full bu 2 hu", ZhuanYuFull, 3, []int32{1, 3}, []int64{-6, 3, 0, 3}},
+		{"full bu 3 hu", ZhuanYuFull, 3, []int32{1, 2, 3}, []int64{-9, 3, 3, 3}},
+		{"full zhi 1 hu", ZhuanYuFull, 2, []int32{1}, []int64{-2, 2, 0, 0}},
+		{"full zhi 2 hu", ZhuanYuFull, 2, []int32{1, 3}, []int64{-4, 2, 0, 2}},
+		{"full zhi 3 hu", ZhuanYuFull, 2, []int32{1, 2, 3}, []int64{-6, 2, 2, 2}},
+		{"full zhi chagua 1 hu", ZhuanYuFull, 4, []int32{1}, []int64{-4, 4, 0, 0}},
+		{"full zhi chagua 2 hu", ZhuanYuFull, 4, []int32{1, 3}, []int64{-8, 4, 0, 4}},
+		{"full zhi chagua 3 hu", ZhuanYuFull, 4, []int32{1, 2, 3}, []int64{-12, 4, 4, 4}},
+		{"full an 1 hu", ZhuanYuFull, 6, []int32{1}, []int64{-6, 6, 0, 0}},
+		{"full an 2 hu", ZhuanYuFull, 6, []int32{1, 3}, []int64{-12, 6, 0, 6}},
+		{"full an 3 hu", ZhuanYuFull, 6, []int32{1, 2, 3}, []int64{-18, 6, 6, 6}},
+		{"first bu 1 hu", ZhuanYuFirst, 3, []int32{1}, []int64{-3, 3, 0, 0}},
+		{"first bu 2 hu", ZhuanYuFirst, 3, []int32{1, 3}, []int64{-3, 3, 0, 0}},
+		{"first bu 3 hu", ZhuanYuFirst, 3, []int32{1, 2, 3}, []int64{-3, 3, 0, 0}},
+		{"first zhi 1 hu", ZhuanYuFirst, 2, []int32{1}, []int64{-2, 2, 0, 0}},
+		{"first zhi 2 hu", ZhuanYuFirst, 2, []int32{1, 3}, []int64{-2, 2, 0, 0}},
+		{"first zhi 3 hu", ZhuanYuFirst, 2, []int32{1, 2, 3}, []int64{-2, 2, 0, 0}},
+		{"first zhi chagua 1 hu", ZhuanYuFirst, 4, []int32{1}, []int64{-4, 4, 0, 0}},
+		{"first zhi chagua 2 hu", ZhuanYuFirst, 4, []int32{1, 3}, []int64{-4, 4, 0, 0}},
+		{"first zhi chagua 3 hu", ZhuanYuFirst, 4, []int32{1, 2, 3}, []int64{-4, 4, 0, 0}},
+		{"first an 1 hu", ZhuanYuFirst, 6, []int32{1}, []int64{-6, 6, 0, 0}},
+		{"first an 2 hu", ZhuanYuFirst, 6, []int32{1, 3}, []int64{-6, 6, 0, 0}},
+		{"first an 3 hu", ZhuanYuFirst, 6, []int32{1, 2, 3}, []int64{-6, 6, 0, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := zhuanYuScores(4, 0, tt.huSeats, tt.win, tt.split)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("zhuanYuScores = %v, want %v", got, tt.want)
+			}
+			sum := int64(0)
+			for _, v := range got {
+				sum += v
+			}
+			if sum != 0 {
+				t.Errorf("scores %v do not sum to 0", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package mjsc
+
+import (
+	"time"
+
+	"github.com/kevin-chtw/tw_common/gamebase/mahjong"
+	"github.com/kevin-chtw/tw_mjsc_svr/pbmjsc"
+)
+
+const (
+	// 断线重连时的牌局阶段
+	PhaseDeal    = 0 //发牌
+	PhaseSwap    = 1 //换三张
+	PhaseDingQue = 2 //定缺
+	PhasePlay    = 3 //打牌
+)
+
+// pendingState 有待玩家响应请求的状态
+type pendingState interface {
+	pendingOperates(seat int32) *mahjong.Operates
+}
+
+// OnReconnect 玩家断线重连，基础牌局数据由基类下发后补发血战相关状态
+func (g *Game) OnReconnect(seat int32) {
+	if !g.IsValidSeat(seat) {
+		return
+	}
+	snapshot := g.sender.buildSnapshot(seat)
+	g.sender.sendMsg(snapshot, seat)
+	if snapshot.Phase == PhasePlay {
+		g.sender.SendCallDataAck(seat)
+	}
+	if in, ok := g.play.intents[seat]; ok {
+		g.sender.sendIntentAck(seat, in)
+	}
+	if pref := g.loadPreference(seat); pref != nil {
+		g.sender.sendPreferenceAck(seat, pref)
+	}
+	if ps, ok := g.CurState.(pendingState); ok {
+		if operates := ps.pendingOperates(seat); operates != nil {
+			g.sender.SendRequestAck(seat, operates)
+		}
+	}
+	// 换三张、定缺还未提交时补发请求
+	if snapshot.Phase == PhaseSwap && !snapshot.SwapSubmitted[seat] {
+		g.sender.sendSwapTilesAck(seat)
+	}
+	if snapshot.Phase == PhaseDingQue && !snapshot.QueSelected[seat] {
+		g.sender.sendDingQueAck(seat)
+	}
+}
+
+// buildSnapshot 构造某座位视角的血战状态快照
+func (s *Sender) buildSnapshot(seat int32) *pbmjsc.SCSnapshotAck {
+	g := s.game
+	count := g.GetPlayerCount()
+	ack := &pbmjsc.SCSnapshotAck{
+		Phase:         PhasePlay,
+		SwapSubmitted: make([]bool, count),
+		QueColors:     make([]int32, count),
+		QueSelected:   make([]bool, count),
+		OutSeats:      make([]int32, 0),
+	}
+
+	switch st := g.CurState.(type) {
+	case *StateInit, *StateDeal:
+		ack.Phase = PhaseDeal
+	case *StateSwapTiles:
+		ack.Phase = PhaseSwap
+		for i, swap := range st.swapTiles {
+			if swap == nil {
+				continue
+			}
+			ack.SwapSubmitted[i] = true
+			if int32(i) == seat {
+				ack.SwapTiles = swap.Tiles
+			}
+		}
+	case *StateDingque:
+		ack.Phase = PhaseDingQue
+	}
+
+	for i := range count {
+		color, ok := g.play.queColors[i]
+		ack.QueSelected[i] = ok
+		ack.QueColors[i] = int32(mahjong.ColorUndefined)
+		// 定缺阶段只能看到自己选的颜色
+		if ok && (i == seat || ack.Phase == PhasePlay) {
+			ack.QueColors[i] = int32(color)
+		}
+		if g.GetPlayer(i).IsOut() {
+			ack.OutSeats = append(ack.OutSeats, i)
+		}
+	}
+
+	pending := ack.Phase == PhaseSwap && !ack.SwapSubmitted[seat] || ack.Phase == PhaseDingQue && !ack.QueSelected[seat]
+	if ps, ok := g.CurState.(pendingState); ok {
+		pending = ps.pendingOperates(seat) != nil
+	}
+	if pending {
+		ack.RequestRemainMs = max(time.Until(g.deadline).Milliseconds(), 0)
+	}
+	return ack
+}
+
+func (s *StateDiscard) pendingOperates(seat int32) *mahjong.Operates {
+	if seat != s.game.play.GetCurSeat() {
+		return nil
+	}
+	return s.operates
+}
+
+func (s *StateWait) pendingOperates(seat int32) *mahjong.Operates {
+	return pendingWaitOperates(s.operatesForSeats, s.reqOperateForSeats, seat)
+}
+
+func (s *StateAfterBukon) pendingOperates(seat int32) *mahjong.Operates {
+	return pendingWaitOperates(s.operatesForSeats, s.reqOperateForSeats, seat)
+}
+
+// pendingWaitOperates 可以碰杠胡且尚未响应的座位
+func pendingWaitOperates(operates []*mahjong.Operates, reqs map[int32]int, seat int32) *mahjong.Operates {
+	if seat < 0 || int(seat) >= len(operates) || operates[seat] == nil {
+		return nil
+	}
+	if _, ok := reqs[seat]; ok || operates[seat].Value == mahjong.OperatePass {
+		return nil
+	}
+	return operates[seat]
+}
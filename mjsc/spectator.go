@@ -0,0 +1,316 @@
+package mjsc
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/kevin-chtw/tw_common/gamebase/game"
+	"github.com/kevin-chtw/tw_common/gamebase/mahjong"
+	"github.com/kevin-chtw/tw_mjsc_svr/conf"
+	"github.com/kevin-chtw/tw_mjsc_svr/pbmjsc"
+	"github.com/kevin-chtw/tw_proto/game/pbmj"
+	"github.com/kevin-chtw/tw_proto/game/pbsc"
+	pitaya "github.com/topfreegames/pitaya/v3/pkg"
+	"github.com/topfreegames/pitaya/v3/pkg/component"
+	"github.com/topfreegames/pitaya/v3/pkg/logger"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+const spectateRoute = "onspectate"
+
+var (
+	seatTables sync.Map // 玩家uid -> seatTable，观战时按玩家找桌，终局或比赛释放时删除
+	pushFunc   func(uid string, msg proto.Message)
+)
+
+// seatTable 玩家所在桌及其配置
+type seatTable struct {
+	table   *game.Table
+	profile *conf.Profile
+}
+
+// delayedMsg 延迟下发的公开消息
+type delayedMsg struct {
+	at  time.Time
+	msg proto.Message
+}
+
+// spectatorFeed 一桌的观战推送：普通观众延迟收到过滤后的公开消息，管理员实时收到全部消息
+type spectatorFeed struct {
+	mu         sync.Mutex
+	watchers   map[string]bool // uid -> 是否全信息
+	lastFull   proto.Message   // 上一条转发的消息，同一消息发给多个座位时只转发一次
+	lastPublic proto.Message   // 上一条公开消息，按座位分别构造的消息过滤后相同时只推送一次
+	queue      chan delayedMsg
+	closed     bool // 比赛已释放，不再接受观众
+}
+
+// loadFeed 牌桌当前比赛的观战推送，比赛已释放时为空
+func loadFeed(t *game.Table) *spectatorFeed {
+	v, ok := matches.Load(t)
+	if !ok {
+		return nil
+	}
+	return v.(*match).feed
+}
+
+// watch 加入观战，重复加入时更新观战模式；比赛已释放时返回 false
+func (f *spectatorFeed) watch(uid string, full bool) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return false
+	}
+	f.watchers[uid] = full
+	if f.queue == nil {
+		f.queue = make(chan delayedMsg, 1024)
+		go f.run(f.queue)
+	}
+	return true
+}
+
+func (f *spectatorFeed) unwatch(uid string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.watchers, uid)
+	if len(f.watchers) == 0 && f.queue != nil {
+		close(f.queue)
+		f.queue = nil
+	}
+}
+
+// publish 转发一条发给玩家的消息，view 为普通观众看到的消息，为空时不转发给普通观众。
+// 推送在锁外进行，避免阻塞牌局
+func (f *spectatorFeed) publish(msg, view proto.Message, delay time.Duration) {
+	f.mu.Lock()
+	if len(f.watchers) == 0 || msg == f.lastFull {
+		f.mu.Unlock()
+		return
+	}
+	f.lastFull = msg
+	full := f.uids(true)
+	if view != nil && (view == msg || !proto.Equal(view, f.lastPublic)) {
+		f.lastPublic = view
+		select {
+		case f.queue <- delayedMsg{at: time.Now().Add(delay), msg: view}:
+		default:
+			logger.Log.Warnf("spectator queue full, drop %T", view)
+		}
+	}
+	f.mu.Unlock()
+
+	for _, uid := range full {
+		push(uid, msg)
+	}
+}
+
+// uids 全信息或普通观众，需持有锁
+func (f *spectatorFeed) uids(full bool) []string {
+	uids := make([]string, 0, len(f.watchers))
+	for uid, v := range f.watchers {
+		if v == full {
+			uids = append(uids, uid)
+		}
+	}
+	return uids
+}
+
+// close 比赛释放，停止推送
+func (f *spectatorFeed) close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	clear(f.watchers)
+	if f.queue != nil {
+		close(f.queue)
+		f.queue = nil
+	}
+}
+
+func (f *spectatorFeed) run(queue chan delayedMsg) {
+	for item := range queue {
+		time.Sleep(time.Until(item.at))
+		f.mu.Lock()
+		uids := f.uids(false)
+		f.mu.Unlock()
+		for _, uid := range uids {
+			push(uid, item.msg)
+		}
+	}
+}
+
+func push(uid string, msg proto.Message) {
+	if pushFunc == nil {
+		return
+	}
+	data, err := anypb.New(msg)
+	if err != nil {
+		logger.Log.Errorf("pack spectator msg: %v", err)
+		return
+	}
+	pushFunc(uid, &pbsc.SCAck{Ack: data})
+}
+
+// spectatorView 普通观众看到的消息：去掉手牌、换牌、听牌、暗杠牌等私有信息，请求类消息不下发
+func (g *Game) spectatorView(msg proto.Message) proto.Message {
+	switch ack := msg.(type) {
+	case *pbmj.MJOpenDoorAck:
+		view := proto.Clone(ack).(*pbmj.MJOpenDoorAck)
+		view.Tiles = nil
+		return view
+	case *pbmj.MJDrawAck:
+		view := proto.Clone(ack).(*pbmj.MJDrawAck)
+		view.Tile = int32(mahjong.TileNull)
+		view.CallData = nil
+		return view
+	case *pbmj.MJPonAck:
+		view := proto.Clone(ack).(*pbmj.MJPonAck)
+		view.CallData = nil
+		return view
+	case *pbmj.MJKonAck:
+		view := proto.Clone(ack).(*pbmj.MJKonAck)
+		if g.isAnKon(ack.Seat, mahjong.Tile(ack.Tile)) {
+			view.Tile = int32(mahjong.TileNull)
+		}
+		clearPrivate(view.ProtoReflect())
+		return view
+	case *pbmj.MJScoreChangeAck:
+		if ack.Reason != int32(mahjong.ScoreReasonAnKon) {
+			return msg
+		}
+		view := proto.Clone(ack)
+		clearField(view.ProtoReflect(), "tile")
+		return view
+	case *pbmj.MJDiscardAck, *pbmj.MJHuAck, *pbmj.MJResultAck:
+		view := proto.Clone(ack)
+		clearPrivate(view.ProtoReflect())
+		return view
+	case *pbsc.SCSwapFinishAck:
+		view := proto.Clone(ack).(*pbsc.SCSwapFinishAck)
+		view.Tiles = nil
+		return view
+	case *pbsc.SCSwapTilesResultAck:
+		view := proto.Clone(ack).(*pbsc.SCSwapTilesResultAck)
+		for _, st := range view.SwapTiles {
+			st.Tiles = nil
+		}
+		return view
+	case *pbsc.SCDingQueFinishAck:
+		view := proto.Clone(ack).(*pbsc.SCDingQueFinishAck)
+		view.Color = int32(mahjong.ColorUndefined)
+		return view
+	case *pbmj.MJGameStartAck, *pbsc.SCSwapTilesAck, *pbsc.SCDingQueAck,
+		*pbsc.SCDingQueResultAck, *pbmjsc.SCDuplicateResultAck, *pbmjsc.SCMatchResultAck:
+		return msg
+	default:
+		return nil
+	}
+}
+
+// isAnKon 该座位的这张杠牌是否暗杠
+func (g *Game) isAnKon(seat int32, tile mahjong.Tile) bool {
+	if !g.IsValidSeat(seat) {
+		return false
+	}
+	for _, kon := range g.play.GetPlayData(seat).GetKonGroups() {
+		if kon.Tile == tile {
+			return kon.Type == mahjong.KonTypeAn
+		}
+	}
+	return false
+}
+
+// clearPrivate 清除消息（包括嵌套消息）中按座位下发的听牌数据
+func clearPrivate(m protoreflect.Message) {
+	clearField(m, "call_data")
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case fd.Message() == nil || fd.IsMap():
+		case fd.IsList():
+			for i := range v.List().Len() {
+				clearPrivate(v.List().Get(i).Message())
+			}
+		default:
+			clearPrivate(v.Message())
+		}
+		return true
+	})
+}
+
+// clearField 清除指定名字的字段，消息没有该字段时忽略
+func clearField(m protoreflect.Message, name protoreflect.Name) {
+	if fd := m.Descriptor().Fields().ByName(name); fd != nil {
+		m.Clear(fd)
+	}
+}
+
+// publishSpectators 将发给玩家的消息转给本桌观众
+func (g *Game) publishSpectators(msg proto.Message) {
+	g.match.feed.publish(msg, g.spectatorView(msg), time.Duration(g.profile.SpectateDelay)*time.Second)
+}
+
+// closeSpectators 终局时解除玩家与本桌的关联，观战推送随比赛一起释放（见 releaseMatch）
+func (g *Game) closeSpectators() {
+	for seat := range g.GetPlayerCount() {
+		seatTables.CompareAndDelete(g.GetPlayer(seat).Uid, seatTable{table: g.table, profile: g.profile})
+	}
+}
+
+// releaseSeatTables 比赛释放时删除仍指向本桌的观战登记（牌局中途解散时终局不会执行）
+func releaseSeatTables(t *game.Table) {
+	seatTables.Range(func(uid, v any) bool {
+		if v.(seatTable).table == t {
+			seatTables.CompareAndDelete(uid, v)
+		}
+		return true
+	})
+}
+
+// SpectatorService 观战请求
+type SpectatorService struct {
+	component.Base
+	app pitaya.Pitaya
+}
+
+func NewSpectatorService(app pitaya.Pitaya) *SpectatorService {
+	pushFunc = func(uid string, msg proto.Message) {
+		if _, err := app.SendPushToUsers(spectateRoute, msg, []string{uid}, conf.Get().Frontend); err != nil {
+			logger.Log.Warnf("push spectator %s: %v", uid, err)
+		}
+	}
+	return &SpectatorService{app: app}
+}
+
+// Watch 观看某玩家所在的桌，全信息观战仅限管理员
+func (s *SpectatorService) Watch(ctx context.Context, req *pbmjsc.SCWatchReq) (*pbmjsc.SCWatchAck, error) {
+	uid := s.app.GetSessionFromCtx(ctx).UID()
+	if uid == "" {
+		return nil, errors.New("not logged in")
+	}
+	v, ok := seatTables.Load(req.TargetUid)
+	if !ok {
+		return nil, errors.New("player not in game")
+	}
+	st := v.(seatTable)
+	f := loadFeed(st.table)
+	if f == nil {
+		return nil, errors.New("player not in game")
+	}
+	if req.Stop {
+		f.unwatch(uid)
+		return &pbmjsc.SCWatchAck{}, nil
+	}
+	admin := conf.Get().IsAdmin(uid)
+	if !st.profile.Spectate && !admin {
+		return nil, errors.New("table does not allow spectators")
+	}
+	full := req.Full && admin
+	if !f.watch(uid, full) {
+		return nil, errors.New("player not in game")
+	}
+	return &pbmjsc.SCWatchAck{Full: full}, nil
+}
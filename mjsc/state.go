@@ -1,31 +1,47 @@
 package mjsc
 
 import (
+	"time"
+
 	"github.com/kevin-chtw/tw_common/gamebase/mahjong"
-	"github.com/kevin-chtw/tw_mjsc_svr/ai"
+	"github.com/kevin-chtw/tw_mjsc_svr/metrics"
+	"google.golang.org/protobuf/proto"
 )
 
 type State struct {
 	*mahjong.State
 	game *Game
+	name string // 状态名，用于统计和 trace
 }
 
-func NewState(game mahjong.IGame) *State {
+func NewState(game mahjong.IGame, name string) *State {
 	g := game.(*Game)
+	g.enterState(name)
 	return &State{
 		State: mahjong.NewState(g.Game, g.sender.Sender),
 		game:  g,
+		name:  name,
 	}
 }
 
-// WaitAni 覆盖基类方法：训练模式下跳过动画等待
+// WaitAni 覆盖基类方法：本桌配置不等待动画时（如训练桌）立即执行
 func (s *State) WaitAni(reqFn func()) {
-	if ai.IsTrainingMode() {
-		// 训练模式：立即执行，不等待动画
+	if !s.game.profile.AnimationWait {
 		reqFn()
 		return
 	}
 
-	// 生产模式：等待5秒动画
+	// 等待5秒动画
 	s.State.WaitAni(reqFn)
 }
+
+// asyncMsgTimer 记录本状态的超时时间（断线重连下发剩余时间）和耗时统计后启动计时
+func (s *State) asyncMsgTimer(onMsg func(seat int32, msg proto.Message) error, d time.Duration, onTimeout func()) {
+	now := time.Now()
+	s.game.deadline = now.Add(d)
+	s.game.timedState, s.game.stateStart = s.name, now
+	s.AsyncMsgTimer(onMsg, d, func() {
+		metrics.StateTimeouts.WithLabelValues(s.name).Inc()
+		onTimeout()
+	})
+}
@@ -1,7 +1,6 @@
 package mjsc
 
 import (
-	"errors"
 	"time"
 
 	"github.com/kevin-chtw/tw_common/gamebase/mahjong"
@@ -14,15 +13,17 @@ type StateAfterBukon struct {
 	konType            mahjong.KonType
 	operatesForSeats   []*mahjong.Operates // 每个座位可执行的操作
 	reqOperateForSeats map[int32]int       // 每个座位已请求的操作
+	playerReqSeats     map[int32]bool      // 玩家主动请求（而非托管、预设或超时）的座位
 }
 
 func NewStateAfterBukon(game mahjong.IGame, args ...any) mahjong.IState {
 	s := &StateAfterBukon{
-		State:   NewState(game),
+		State:   NewState(game, "after_bukon"),
 		konType: args[0].(mahjong.KonType),
 	}
 	s.operatesForSeats = make([]*mahjong.Operates, s.game.GetPlayerCount())
 	s.reqOperateForSeats = make(map[int32]int)
+	s.playerReqSeats = make(map[int32]bool)
 	return s
 }
 
@@ -36,14 +37,16 @@ func (s *StateAfterBukon) OnEnter() {
 		operates := s.game.play.FetchAfterBuKonOperates(i, newCheckerPao(s.game.play), s.game.sender.Sender)
 		s.operatesForSeats[i] = operates
 
-		if operates.Value != mahjong.OperatePass && !s.game.GetPlayer(i).IsTrusted() {
+		if operate, ok := s.game.presetOperate(i, operates); ok {
+			s.reqOperateForSeats[i] = operate
+		} else if operates.Value != mahjong.OperatePass && !s.game.GetPlayer(i).IsTrusted() {
 			s.game.sender.SendRequestAck(i, operates)
 		} else {
 			s.reqOperateForSeats[i] = mahjong.OperatePass
 		}
 	}
 	timeout := s.game.GetRule().GetValue(RuleWaitTime) + 1
-	s.AsyncMsgTimer(s.OnMsg, time.Second*time.Duration(timeout), s.OnTimeout)
+	s.asyncMsgTimer(s.OnMsg, time.Second*time.Duration(timeout), s.OnTimeout)
 	s.tryHandleAction()
 }
 
@@ -52,14 +55,18 @@ func (s *StateAfterBukon) OnMsg(seat int32, msg proto.Message) error {
 	if !ok {
 		return nil
 	}
-	if optReq == nil || optReq.Seat != seat || !s.game.sender.IsRequestID(seat, optReq.Requestid) {
-		return errors.New("invalid request")
+	if optReq.Seat != seat {
+		return reject(RejectWrongSeat, "request for seat %d", optReq.Seat)
+	}
+	if !s.game.sender.IsRequestID(seat, optReq.Requestid) {
+		return reject(RejectStaleRequest, "request id %d", optReq.Requestid)
 	}
 
 	if !s.isValidOperate(seat, int(optReq.RequestType)) {
-		return errors.New("invalid operate")
+		return reject(RejectOperateNotOffered, "operate %d", optReq.RequestType)
 	}
 	s.reqOperateForSeats[seat] = int(optReq.RequestType)
+	s.playerReqSeats[seat] = true
 	s.tryHandleAction()
 	return nil
 }
@@ -78,33 +85,41 @@ func (s *StateAfterBukon) isValidOperate(seat int32, operate int) bool {
 func (s *StateAfterBukon) tryHandleAction() {
 	curSeat := s.game.play.GetCurSeat()
 	huSeats := make([]int32, 0)
+	jieHu := s.game.GetRule().GetValue(RuleJieHu) != 0 // 截胡：离点炮者最近的胡牌者决定后不再等待其他人
 	for i := int32(1); i < s.game.GetPlayerCount(); i++ {
 		seat := mahjong.GetNextSeat(curSeat, i, s.game.GetPlayerCount())
 		if operate, ok := s.getReqOperate(seat); ok {
 			if operate == mahjong.OperateHu {
 				huSeats = append(huSeats, seat)
+				if jieHu {
+					break
+				}
 			}
 		} else if s.getMaxOperate(seat) == mahjong.OperateHu {
 			return
 		}
 	}
 
+	s.game.play.recordDeclines(s.operatesForSeats, s.reqOperateForSeats, s.playerReqSeats)
 	if len(huSeats) > 0 {
 		s.excuteHu(huSeats)
 	} else {
 		if s.konType == mahjong.KonTypeBu {
 			scores := s.game.scorelator.CalcKon(mahjong.ScoreReasonBuKon, s.game.play.GetCurSeat(), mahjong.SeatNull, 1, 1)
+			s.game.recordKon(s.game.play.GetCurSeat(), scores)
 			s.game.sender.SendScoreChangeAck(mahjong.ScoreReasonBuKon, scores, mahjong.TileNull, mahjong.SeatNull, nil)
 		}
 		s.game.SetNextState(NewStateDraw)
 	}
 }
 
+// excuteHu 抢杠胡：补杠不计分，杠牌玩家之前的杠分按呼叫转移处理
 func (s *StateAfterBukon) excuteHu(huSeats []int32) {
-	multiples := s.game.play.PaoHu(huSeats)
-	scores := s.game.scorelator.CalcMulti(mahjong.SeatNull, mahjong.ScoreReasonHu, multiples)
+	s.game.play.markJieHu(huSeats, s.operatesForSeats)
+	s.game.recordHu(huSeats, s.game.play.GetCurSeat(), false)
 	s.game.sender.SendHuAck(huSeats, s.game.play.GetCurSeat())
-	s.game.sender.SendScoreChangeAck(mahjong.ScoreReasonHu, scores, s.game.play.GetCurTile(), s.game.play.GetCurSeat(), huSeats)
+	s.game.settlePaoHu(huSeats)
+	s.game.play.jieHu = false
 	for _, seat := range huSeats {
 		s.game.GetPlayer(seat).SetOut()
 	}
@@ -10,11 +10,12 @@ type StateDeal struct {
 
 func NewStateDeal(game mahjong.IGame, args ...any) mahjong.IState {
 	return &StateDeal{
-		State: NewState(game),
+		State: NewState(game, "deal"),
 	}
 }
 
 func (s *StateDeal) OnEnter() {
+	s.game.seedWall()
 	s.game.play.Deal()
 
 	s.game.sender.SendOpenDoorAck()
@@ -1,9 +1,9 @@
 package mjsc
 
 import (
-	"errors"
 	"time"
 
+	"github.com/kevin-chtw/tw_common/gamebase/game"
 	"github.com/kevin-chtw/tw_common/gamebase/mahjong"
 	"github.com/kevin-chtw/tw_proto/game/pbsc"
 	"google.golang.org/protobuf/proto"
@@ -15,14 +15,14 @@ type StateDingque struct {
 
 func NewStateDingque(game mahjong.IGame, args ...any) mahjong.IState {
 	s := &StateDingque{
-		State: NewState(game),
+		State: NewState(game, "dingque"),
 	}
 	return s
 }
 
 func (s *StateDingque) OnEnter() {
-	s.game.sender.sendDingQueAck()
-	s.AsyncMsgTimer(s.OnMsg, time.Second*time.Duration(8), s.OnTimeout)
+	s.game.sender.sendDingQueAck(game.SeatAll)
+	s.asyncMsgTimer(s.OnMsg, time.Second*time.Duration(8), s.OnTimeout)
 }
 
 func (s *StateDingque) OnMsg(seat int32, msg proto.Message) error {
@@ -30,16 +30,16 @@ func (s *StateDingque) OnMsg(seat int32, msg proto.Message) error {
 	if !ok {
 		return nil
 	}
-	if req == nil || !s.game.sender.IsRequestID(seat, req.Requestid) {
-		return errors.New("invalid request")
+	if !s.game.sender.IsRequestID(seat, req.Requestid) {
+		return reject(RejectStaleRequest, "request id %d", req.Requestid)
 	}
 
 	if _, ok := s.game.play.queColors[seat]; ok {
-		return errors.New("color already selected")
+		return reject(RejectAlreadySubmitted, "color already selected")
 	}
 
 	if req.Color < int32(mahjong.ColorCharacter) || req.Color > int32(mahjong.ColorDot) {
-		return errors.New("invalid color")
+		return reject(RejectInvalidColor, "color %d", req.Color)
 	}
 
 	s.game.play.queColors[seat] = mahjong.EColor(req.Color)
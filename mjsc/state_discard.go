@@ -1,26 +1,29 @@
 package mjsc
 
 import (
-	"errors"
 	"time"
 
 	"github.com/kevin-chtw/tw_common/gamebase/mahjong"
 	"github.com/kevin-chtw/tw_proto/game/pbmj"
-	"github.com/topfreegames/pitaya/v3/pkg/logger"
 	"google.golang.org/protobuf/proto"
 )
 
 type StateDiscard struct {
 	*State
+	drawn    mahjong.Tile // 本次摸到的牌，碰牌后出牌时为空
 	operates *mahjong.Operates
 	handlers map[int32]func(tile mahjong.Tile)
 }
 
 func NewStateDiscard(game mahjong.IGame, args ...any) mahjong.IState {
 	s := &StateDiscard{
-		State:    NewState(game),
+		State:    NewState(game, "discard"),
+		drawn:    mahjong.TileNull,
 		handlers: make(map[int32]func(tile mahjong.Tile)),
 	}
+	if len(args) > 0 {
+		s.drawn = args[0].(mahjong.Tile)
+	}
 	s.handlers[mahjong.OperateDiscard] = s.discard
 	s.handlers[mahjong.OperateKon] = s.kon
 	s.handlers[mahjong.OperateHu] = s.hu
@@ -35,24 +38,35 @@ func (s *StateDiscard) OnEnter() {
 		s.discard(mahjong.TileNull)
 		return
 	}
-	s.AsyncMsgTimer(s.OnMsg, time.Second*time.Duration(discardTime), s.OnTimeout)
+	if s.autoDiscard(s.drawn) {
+		return
+	}
+	s.asyncMsgTimer(s.OnMsg, time.Second*time.Duration(discardTime), s.OnTimeout)
 }
 
 func (s *StateDiscard) OnMsg(seat int32, msg proto.Message) error {
 	if seat != s.game.play.GetCurSeat() {
-		return errors.New("invalid seat")
+		return reject(RejectWrongSeat, "not your turn")
 	}
 
 	optReq, ok := msg.(*pbmj.MJRequestReq)
 	if !ok {
 		return nil
 	}
-	if optReq == nil || optReq.Seat != seat || !s.game.sender.IsRequestID(seat, optReq.Requestid) {
-		return errors.New("invalid request")
+	if optReq.Seat != seat {
+		return reject(RejectWrongSeat, "request for seat %d", optReq.Seat)
+	}
+	if !s.game.sender.IsRequestID(seat, optReq.Requestid) {
+		return reject(RejectStaleRequest, "request id %d", optReq.Requestid)
 	}
 
 	if !s.operates.HasOperate(optReq.RequestType) {
-		return errors.New("invalid operate")
+		return reject(RejectOperateNotOffered, "operate %d", optReq.RequestType)
+	}
+	if optReq.RequestType == mahjong.OperateDiscard {
+		if err := s.game.play.checkDiscard(seat, mahjong.Tile(optReq.Tile)); err != nil {
+			return err
+		}
 	}
 	if handler, exists := s.handlers[optReq.RequestType]; exists {
 		handler(mahjong.Tile(optReq.Tile))
@@ -63,6 +77,7 @@ func (s *StateDiscard) OnMsg(seat int32, msg proto.Message) error {
 func (s *StateDiscard) discard(tile mahjong.Tile) {
 	if s.game.play.discard(tile) {
 		s.game.sender.SendDiscardAck()
+		s.game.turn++
 		s.game.SetNextState(NewStateWait)
 	}
 }
@@ -78,6 +93,7 @@ func (s *StateDiscard) kon(tile mahjong.Tile) {
 	} else if s.game.play.TryKon(tile, mahjong.KonTypeAn) {
 		s.game.sender.SendKonAck(s.game.play.GetCurSeat(), tile, mahjong.KonTypeAn)
 		scores := s.game.scorelator.CalcKon(mahjong.ScoreReasonAnKon, s.game.play.GetCurSeat(), mahjong.SeatNull, 2, 2)
+		s.game.recordKon(s.game.play.GetCurSeat(), scores)
 		s.game.sender.SendScoreChangeAck(mahjong.ScoreReasonAnKon, scores, s.game.play.GetCurTile(), mahjong.SeatNull, nil)
 		s.game.SetNextState(NewStateDraw)
 	}
@@ -87,12 +103,15 @@ func (s *StateDiscard) hu(tile mahjong.Tile) {
 	huSeats := make([]int32, 0)
 	huSeats = append(huSeats, s.game.play.GetCurSeat())
 	var multiples []int64
+	zimo := true
 	paoSeat := s.game.play.IsAfterZhiKon()
 	if s.game.GetRule().GetValue(RuleDianKHSDP) != 0 && paoSeat != mahjong.SeatNull {
 		multiples = s.game.play.DianKonHua(paoSeat)
+		zimo = false
 	} else {
 		multiples = s.game.play.Zimo()
 	}
+	s.game.recordHu(huSeats, paoSeat, zimo)
 	s.game.sender.SendHuAck(huSeats, paoSeat)
 	scores := s.game.scorelator.CalcMulti(s.game.play.GetCurSeat(), mahjong.ScoreReasonHu, multiples)
 	s.game.sender.SendScoreChangeAck(mahjong.ScoreReasonHu, scores, s.game.play.GetCurTile(), paoSeat, huSeats)
@@ -105,7 +124,7 @@ func (s *StateDiscard) OnTimeout() {
 	if s.game.MatchType == "fdtable" {
 		return
 	}
-	logger.Log.Warnf("discard timeout")
+	s.game.logger().WithField("seat", s.game.play.GetCurSeat()).Warnf("discard timeout")
 	s.discard(mahjong.TileNull)
 	//s.game.sender.SendTrustAck(s.game.play.GetCurSeat(), true)
 }
@@ -10,14 +10,13 @@ type StateDraw struct {
 
 func NewStateDraw(game mahjong.IGame, args ...any) mahjong.IState {
 	return &StateDraw{
-		State: NewState(game),
+		State: NewState(game, "draw"),
 	}
 }
 
 func (s *StateDraw) OnEnter() {
-	if s.game.GetRestCount() == 1 {
-		s.game.sender.SendResult(false)
-		s.WaitAni(s.game.OnGameOver)
+	if active := activeSeats(s.outs()); len(active) < 2 {
+		s.lastPlayer(active)
 		return
 	}
 
@@ -26,8 +25,9 @@ func (s *StateDraw) OnEnter() {
 		s.liuJu()
 		return
 	}
+	s.game.play.clearDeclines(s.game.play.GetCurSeat())
 	s.game.sender.SendDrawAck(tile)
-	s.game.SetNextState(NewStateDiscard)
+	s.game.SetNextState(NewStateDiscard, tile)
 }
 
 func (s *StateDraw) liuJu() {
@@ -42,51 +42,111 @@ func (s *StateDraw) liuJu() {
 		}
 	}
 
-	s.game.sender.SendResult(true)
+	s.game.onResult(s.game.sender.sendResult(true))
 	s.WaitAni(s.game.OnGameOver)
 }
 
+// lastPlayer 血战只剩一名（或没有）玩家未胡时直接结束，不再摸牌，剩下的玩家未听牌时退杠、查叫。
+// 此时其他玩家都已出局：查叫没有可赔付的听牌玩家；退杠只有 fdtable 向已出局玩家退还，
+// 其他比赛不产生分数变化，也就不下发
+func (s *StateDraw) lastPlayer(active []int32) {
+	for _, seat := range active {
+		if !s.isCall(seat) {
+			s.tuiKon(seat)
+			s.chaJiao(seat)
+		}
+	}
+	s.game.onResult(s.game.sender.sendResult(false))
+	s.WaitAni(s.game.OnGameOver)
+}
+
+func (s *StateDraw) outs() []bool {
+	outs := make([]bool, s.game.GetPlayerCount())
+	for i := range outs {
+		outs[i] = s.game.GetPlayer(int32(i)).IsOut()
+	}
+	return outs
+}
+
+// activeSeats 还未胡牌（未出局）的座位
+func activeSeats(outs []bool) []int32 {
+	active := make([]int32, 0, len(outs))
+	for i, out := range outs {
+		if !out {
+			active = append(active, int32(i))
+		}
+	}
+	return active
+}
+
 func (s *StateDraw) tuiKon(seat int32) {
 	if s.game.GetRule().GetValue(RuleTuiYu) == 0 {
 		return
 	}
+	outs := s.outs()
 	scoreNodes := s.game.scorelator.GetKonScores(seat)
-	for _, sn := range scoreNodes {
-		scores := make([]int64, len(sn.Scores))
-		konSeatScore := int64(0)
-		for i, v := range sn.Scores {
-			if int32(i) != seat {
-				if s.game.MatchType == "fdtable" || !s.game.GetPlayer(int32(i)).IsOut() {
-					konSeatScore += v
-					scores[i] = -v
-				}
-			}
+	for index, sn := range scoreNodes {
+		if s.game.konMoved(seat, index) {
+			continue
 		}
-		scores[seat] = konSeatScore
+		scores := tuiKonScores(seat, sn.Scores, outs, s.game.MatchType == "fdtable")
 		final := s.game.scorelator.CalcScores(mahjong.SeatNull, mahjong.ScoreReasonTuiKon, scores)
-		s.game.sender.SendScoreChangeAck(mahjong.ScoreReasonTuiKon, final, mahjong.TileNull, mahjong.SeatNull, nil)
+		if !zeroScores(final) {
+			s.game.sender.SendScoreChangeAck(mahjong.ScoreReasonTuiKon, final, mahjong.TileNull, mahjong.SeatNull, nil)
+		}
 	}
 }
 
+// tuiKonScores 退还一次杠分，已出局的玩家只在 fdtable 退还
+func tuiKonScores(seat int32, konScores []int64, outs []bool, fdtable bool) []int64 {
+	scores := make([]int64, len(konScores))
+	for i, v := range konScores {
+		if int32(i) != seat && (fdtable || !outs[i]) {
+			scores[seat] += v
+			scores[i] = -v
+		}
+	}
+	return scores
+}
+
 func (s *StateDraw) chaJiao(seat int32) {
 	if s.game.GetRule().GetValue(RuleChaJiao) == 0 {
 		return
 	}
-	multis := make([]int64, s.game.GetPlayerCount()) // 预分配数组
+	outs := s.outs()
+	maxMultis := make([]int64, len(outs))
 	for i := range s.game.GetPlayerCount() {
-		if s.game.GetPlayer(i).IsOut() || i == seat {
-			continue
+		if !outs[i] && i != seat {
+			maxMultis[i] = s.maxMulti(i)
 		}
+	}
+	final := s.game.scorelator.CalcMulti(mahjong.SeatNull, mahjong.ScoreReasonChaJiao, chaJiaoMultis(seat, outs, maxMultis))
+	if !zeroScores(final) {
+		s.game.sender.SendScoreChangeAck(mahjong.ScoreReasonChaJiao, final, mahjong.TileNull, mahjong.SeatNull, nil)
+	}
+}
 
-		maxMulti := s.maxMulti(i)
-		if maxMulti <= 0 {
+// chaJiaoMultis 查叫：未听牌的玩家按每个未出局听牌玩家的最大番数赔付
+func chaJiaoMultis(seat int32, outs []bool, maxMultis []int64) []int64 {
+	multis := make([]int64, len(outs))
+	for i, out := range outs {
+		if out || int32(i) == seat || maxMultis[i] <= 0 {
 			continue
 		}
-		multis[i] = maxMulti
-		multis[seat] -= maxMulti
+		multis[i] = maxMultis[i]
+		multis[seat] -= maxMultis[i]
+	}
+	return multis
+}
+
+// zeroScores 分数变化全为0时不下发
+func zeroScores(scores []int64) bool {
+	for _, v := range scores {
+		if v != 0 {
+			return false
+		}
 	}
-	final := s.game.scorelator.CalcMulti(mahjong.SeatNull, mahjong.ScoreReasonChaJiao, multis)
-	s.game.sender.SendScoreChangeAck(mahjong.ScoreReasonChaJiao, final, mahjong.TileNull, mahjong.SeatNull, nil)
+	return true
 }
 
 func (s *StateDraw) isCall(seat int32) bool {
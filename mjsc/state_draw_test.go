@@ -0,0 +1,77 @@
+package mjsc
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestActiveSeats(t *testing.T) {
+	tests := []struct {
+		name   string
+		outs   []bool
+		active []int32
+		end    bool // 是否不再摸牌直接结束
+	}{
+		{"0 out", []bool{false, false, false, false}, []int32{0, 1, 2, 3}, false},
+		{"1 out", []bool{false, true, false, false}, []int32{0, 2, 3}, false},
+		{"2 out", []bool{true, false, true, false}, []int32{1, 3}, false},
+		{"3 out", []bool{true, true, false, true}, []int32{2}, true},
+		{"all out", []bool{true, true, true, true}, []int32{}, true},
+		{"2 players 1 out", []bool{false, true}, []int32{0}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			active := activeSeats(tt.outs)
+			if !slices.Equal(active, tt.active) {
+				t.Errorf("activeSeats = %v, want %v", active, tt.active)
+			}
+			if end := len(active) < 2; end != tt.end {
+				t.Errorf("end = %v, want %v", end, tt.end)
+			}
+		})
+	}
+}
+
+// TestOutsSettle 座位0未听牌，分别有0-3名玩家已出局时：是否不再摸牌直接结束，以及退杠、查叫的分数变化。
+// 座位0有一次收了每家1分的杠，座位1、3听牌最大番数为2、4
+func TestOutsSettle(t *testing.T) {
+	konScores := []int64{3, -1, -1, -1}
+	maxMultis := []int64{0, 2, 0, 4}
+	tests := []struct {
+		name     string
+		outs     []bool
+		end      bool
+		tuiKon   []int64 // 非 fdtable
+		tuiKonFd []int64
+		chaJiao  []int64
+	}{
+		{"0 out", []bool{false, false, false, false}, false,
+			[]int64{-3, 1, 1, 1}, []int64{-3, 1, 1, 1}, []int64{-6, 2, 0, 4}},
+		{"1 out", []bool{false, true, false, false}, false,
+			[]int64{-2, 0, 1, 1}, []int64{-3, 1, 1, 1}, []int64{-4, 0, 0, 4}},
+		{"2 out", []bool{false, true, true, false}, false,
+			[]int64{-1, 0, 0, 1}, []int64{-3, 1, 1, 1}, []int64{-4, 0, 0, 4}},
+		{"3 out", []bool{false, true, true, true}, true,
+			[]int64{0, 0, 0, 0}, []int64{-3, 1, 1, 1}, []int64{0, 0, 0, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if end := len(activeSeats(tt.outs)) < 2; end != tt.end {
+				t.Errorf("end = %v, want %v", end, tt.end)
+			}
+			if got := tuiKonScores(0, konScores, tt.outs, false); !slices.Equal(got, tt.tuiKon) {
+				t.Errorf("tuiKon = %v, want %v", got, tt.tuiKon)
+			}
+			if got := tuiKonScores(0, konScores, tt.outs, true); !slices.Equal(got, tt.tuiKonFd) {
+				t.Errorf("fdtable tuiKon = %v, want %v", got, tt.tuiKonFd)
+			}
+			if got := chaJiaoMultis(0, tt.outs, maxMultis); !slices.Equal(got, tt.chaJiao) {
+				t.Errorf("chaJiao = %v, want %v", got, tt.chaJiao)
+			}
+			// 只剩一人时非 fdtable 的退杠、查叫全为0，不下发
+			if tt.end && (!zeroScores(tuiKonScores(0, konScores, tt.outs, false)) || !zeroScores(chaJiaoMultis(0, tt.outs, maxMultis))) {
+				t.Error("last player settles non-zero scores")
+			}
+		})
+	}
+}
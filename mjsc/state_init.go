@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/kevin-chtw/tw_common/gamebase/mahjong"
+	"github.com/kevin-chtw/tw_mjsc_svr/metrics"
 )
 
 type StateInit struct {
@@ -12,12 +13,17 @@ type StateInit struct {
 
 func NewStateInit(game mahjong.IGame, args ...any) mahjong.IState {
 	return &StateInit{
-		State: NewState(game),
+		State: NewState(game, "init"),
 	}
 }
 
 func (s *StateInit) OnEnter() {
 	s.game.play.Initialize(mahjong.NewPlayData)
+	s.game.results = make([]int64, s.game.GetPlayerCount())
+	s.game.selectBanker()
+	s.game.bindProfile()
+	metrics.GamesStarted.WithLabelValues(s.game.MatchType).Inc()
+	s.game.registerDebug()
 	s.game.sender.SendGameStartAck()
 
 	s.AsyncTimer(time.Second, func() { s.game.SetNextState(NewStateDeal) })
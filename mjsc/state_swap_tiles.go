@@ -1,10 +1,9 @@
 package mjsc
 
 import (
-	"errors"
-	"math/rand"
 	"time"
 
+	"github.com/kevin-chtw/tw_common/gamebase/game"
 	"github.com/kevin-chtw/tw_common/gamebase/mahjong"
 	"github.com/kevin-chtw/tw_proto/game/pbsc"
 	"google.golang.org/protobuf/proto"
@@ -17,15 +16,15 @@ type StateSwapTiles struct {
 
 func NewStateSwapTiles(game mahjong.IGame, args ...any) mahjong.IState {
 	s := &StateSwapTiles{
-		State: NewState(game),
+		State: NewState(game, "swap"),
 	}
 	s.swapTiles = make([]*pbsc.SCSwapTiles, s.game.GetPlayerCount())
 	return s
 }
 
 func (s *StateSwapTiles) OnEnter() {
-	s.game.sender.sendSwapTilesAck()
-	s.AsyncMsgTimer(s.OnMsg, time.Second*time.Duration(8), s.OnTimeout)
+	s.game.sender.sendSwapTilesAck(game.SeatAll)
+	s.asyncMsgTimer(s.OnMsg, time.Second*time.Duration(8), s.OnTimeout)
 }
 
 func (s *StateSwapTiles) OnMsg(seat int32, msg proto.Message) error {
@@ -33,16 +32,16 @@ func (s *StateSwapTiles) OnMsg(seat int32, msg proto.Message) error {
 	if !ok {
 		return nil
 	}
-	if optReq == nil || !s.game.sender.IsRequestID(seat, optReq.Requestid) {
-		return errors.New("invalid request")
+	if !s.game.sender.IsRequestID(seat, optReq.Requestid) {
+		return reject(RejectStaleRequest, "request id %d", optReq.Requestid)
 	}
 
 	if s.swapTiles[seat] != nil {
-		return errors.New("already swapped")
+		return reject(RejectAlreadySubmitted, "already swapped")
 	}
 
 	if !s.game.play.GetPlayData(seat).CanExchangeOut(mahjong.Int32Tile(optReq.Tiles)) {
-		return errors.New("invalid tiles")
+		return reject(RejectInvalidTiles, "cannot swap out %v", optReq.Tiles)
 	}
 	s.swapTiles[seat] = &pbsc.SCSwapTiles{
 		From:  seat,
@@ -67,7 +66,7 @@ func (s *StateSwapTiles) allPlayersSwapped() bool {
 }
 
 func (s *StateSwapTiles) executeSwap() {
-	swapType := rand.Int31n(3)
+	swapType := s.game.rand.Int31n(3)
 	switch swapType {
 	case 1:
 		s.swapClockwise()
@@ -1,10 +1,10 @@
 package mjsc
 
 import (
-	"errors"
 	"time"
 
 	"github.com/kevin-chtw/tw_common/gamebase/mahjong"
+	"github.com/kevin-chtw/tw_mjsc_svr/collusion"
 	"github.com/kevin-chtw/tw_proto/game/pbmj"
 	"google.golang.org/protobuf/proto"
 )
@@ -13,14 +13,16 @@ type StateWait struct {
 	*State
 	operatesForSeats   []*mahjong.Operates // 每个座位可执行的操作
 	reqOperateForSeats map[int32]int       // 每个座位已请求的操作
+	playerReqSeats     map[int32]bool      // 玩家主动请求（而非托管、预设或超时）的座位
 }
 
 func NewStateWait(game mahjong.IGame, args ...any) mahjong.IState {
 	s := &StateWait{
-		State: NewState(game),
+		State: NewState(game, "wait"),
 	}
 	s.operatesForSeats = make([]*mahjong.Operates, s.game.GetPlayerCount())
 	s.reqOperateForSeats = make(map[int32]int)
+	s.playerReqSeats = make(map[int32]bool)
 	return s
 }
 
@@ -33,7 +35,9 @@ func (s *StateWait) OnEnter() {
 		operates := s.game.play.FetchWaitOperates(i, s.game.sender.Sender)
 		s.operatesForSeats[i] = operates
 
-		if operates.Value != mahjong.OperatePass && !s.game.GetPlayer(i).IsTrusted() {
+		if operate, ok := s.game.presetOperate(i, operates); ok {
+			s.setReqOperate(i, operate)
+		} else if operates.Value != mahjong.OperatePass && !s.game.GetPlayer(i).IsTrusted() {
 			s.game.sender.SendRequestAck(i, operates)
 		} else {
 			s.setReqOperate(i, s.getDefaultOperate(i))
@@ -41,7 +45,7 @@ func (s *StateWait) OnEnter() {
 	}
 
 	timeout := s.game.GetRule().GetValue(RuleWaitTime) + 1
-	s.AsyncMsgTimer(s.OnMsg, time.Second*time.Duration(timeout), s.Timeout)
+	s.asyncMsgTimer(s.OnMsg, time.Second*time.Duration(timeout), s.Timeout)
 	s.tryHandleAction()
 }
 
@@ -50,14 +54,18 @@ func (s *StateWait) OnMsg(seat int32, msg proto.Message) error {
 	if !ok {
 		return nil
 	}
-	if optReq == nil || optReq.Seat != seat || !s.game.sender.IsRequestID(seat, optReq.Requestid) {
-		return errors.New("invalid request")
+	if optReq.Seat != seat {
+		return reject(RejectWrongSeat, "request for seat %d", optReq.Seat)
+	}
+	if !s.game.sender.IsRequestID(seat, optReq.Requestid) {
+		return reject(RejectStaleRequest, "request id %d", optReq.Requestid)
 	}
 
 	if !s.isValidOperate(seat, int(optReq.RequestType)) {
-		return errors.New("invalid operate")
+		return reject(RejectOperateNotOffered, "operate %d", optReq.RequestType)
 	}
 	s.setReqOperate(seat, int(optReq.RequestType))
+	s.playerReqSeats[seat] = true
 	s.tryHandleAction()
 	return nil
 }
@@ -91,11 +99,15 @@ func (s *StateWait) getReqOperate(seat int32) (int, bool) {
 func (s *StateWait) tryHandleAction() {
 	curSeat := s.game.play.GetCurSeat()
 	huSeats := make([]int32, 0)
+	jieHu := s.game.GetRule().GetValue(RuleJieHu) != 0 // 截胡：离点炮者最近的胡牌者决定后不再等待其他人
 	for i := int32(1); i < s.game.GetPlayerCount(); i++ {
 		seat := mahjong.GetNextSeat(curSeat, i, s.game.GetPlayerCount())
 		if operate, ok := s.getReqOperate(seat); ok {
 			if operate == mahjong.OperateHu {
 				huSeats = append(huSeats, seat)
+				if jieHu {
+					break
+				}
 			}
 		} else if s.getMaxOperate(seat) == mahjong.OperateHu {
 			return
@@ -103,6 +115,7 @@ func (s *StateWait) tryHandleAction() {
 	}
 
 	if len(huSeats) > 0 {
+		s.game.play.recordDeclines(s.operatesForSeats, s.reqOperateForSeats, s.playerReqSeats)
 		s.excuteHu(huSeats)
 		return
 	}
@@ -125,11 +138,16 @@ func (s *StateWait) tryHandleAction() {
 		}
 	}
 	if isMaxReq {
+		s.game.play.recordDeclines(s.operatesForSeats, s.reqOperateForSeats, s.playerReqSeats)
 		s.excuteOperate(maxOperSeat, maxOper)
 	}
 }
 
 func (s *StateWait) excuteOperate(seat int32, operate int) {
+	s.game.clearKon()
+	if operate == mahjong.OperateKon || operate == mahjong.OperatePon {
+		s.game.play.recordEvent(collusion.KindMeld, s.game.play.GetCurSeat(), seat, 0)
+	}
 	if operate == mahjong.OperateKon {
 		s.game.play.ZhiKon(seat)
 		s.game.sender.SendKonAck(seat, s.game.play.GetCurTile(), mahjong.KonTypeZhi)
@@ -138,12 +156,14 @@ func (s *StateWait) excuteOperate(seat int32, operate int) {
 			otherMulti = 1
 		}
 		scores := s.game.scorelator.CalcKon(mahjong.ScoreReasonZhiKon, seat, s.game.play.GetCurSeat(), 2, int64(otherMulti))
+		s.game.recordKon(seat, scores)
 		s.game.sender.SendScoreChangeAck(mahjong.ScoreReasonZhiKon, scores, s.game.play.GetCurTile(), mahjong.SeatNull, nil)
 		s.toDrawState(seat)
 		return
 	}
 	if operate == mahjong.OperatePon {
 		s.game.play.Pon(seat)
+		s.game.play.clearDeclines(seat)
 		s.game.sender.SendPonAck(seat, s.game.play.GetCurTile(), false)
 		s.toDiscardState(seat)
 		return
@@ -152,12 +172,11 @@ func (s *StateWait) excuteOperate(seat int32, operate int) {
 }
 
 func (s *StateWait) excuteHu(huSeats []int32) {
+	s.game.play.markJieHu(huSeats, s.operatesForSeats)
+	s.game.recordHu(huSeats, s.game.play.GetCurSeat(), false)
 	s.game.sender.SendHuAck(huSeats, s.game.play.GetCurSeat())
-	s.zhuanYu(huSeats)
-
-	multiples := s.game.play.PaoHu(huSeats)
-	scores := s.game.scorelator.CalcMulti(mahjong.SeatNull, mahjong.ScoreReasonHu, multiples)
-	s.game.sender.SendScoreChangeAck(mahjong.ScoreReasonHu, scores, s.game.play.GetCurTile(), s.game.play.GetCurSeat(), huSeats)
+	s.game.settlePaoHu(huSeats)
+	s.game.play.jieHu = false
 	for _, seat := range huSeats {
 		s.game.GetPlayer(seat).SetOut()
 	}
@@ -166,24 +185,6 @@ func (s *StateWait) excuteHu(huSeats []int32) {
 	s.game.SetNextState(NewStateDraw)
 }
 
-func (s *StateWait) zhuanYu(huSeats []int32) {
-	if s.game.GetRule().GetValue(RuleZhuanYu) == 0 || !s.game.play.IsAfterKon() {
-		return
-	}
-	scores := s.game.scorelator.RemoveLastScore()
-	winScore := scores.Scores[s.game.play.GetCurSeat()]
-	avgScore := winScore / int64(len(huSeats))
-	remainder := winScore % int64(len(huSeats))
-	newScores := make([]int64, len(scores.Scores))
-	newScores[s.game.play.GetCurSeat()] = -winScore
-	newScores[huSeats[0]] = avgScore + remainder
-	for i := 1; i < len(huSeats); i++ {
-		newScores[huSeats[i]] += avgScore
-	}
-	final := s.game.scorelator.CalcScores(mahjong.SeatNull, mahjong.ScoreReasonZhuanYu, newScores)
-	s.game.sender.SendScoreChangeAck(mahjong.ScoreReasonZhuanYu, final, s.game.play.GetCurTile(), s.game.play.GetCurSeat(), huSeats)
-}
-
 func (s *StateWait) toDrawState(seat int32) {
 	s.game.play.DoSwitchSeat(seat)
 	s.game.SetNextState(NewStateDraw)
@@ -0,0 +1,52 @@
+package mjsc
+
+import (
+	"context"
+
+	"github.com/kevin-chtw/tw_mjsc_svr/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// startGameSpan 整局一个 span，各状态为其子 span
+func (g *Game) startGameSpan() {
+	matchID, tableID := tableIDs(g.table)
+	g.gameCtx, g.gameSpan = tracing.Tracer().Start(context.Background(), "game", trace.WithAttributes(
+		attribute.Int("match", int(matchID)),
+		attribute.Int("table", int(tableID)),
+		attribute.Int("game", int(g.index)),
+	))
+}
+
+func (g *Game) endGameSpan() {
+	g.endStateSpan()
+	if g.gameSpan == nil {
+		return
+	}
+	for seat := range g.GetPlayerCount() {
+		tracing.UnbindSeat(g.GetPlayer(seat).Uid)
+	}
+	g.gameSpan.SetAttributes(attribute.String("match_type", g.MatchType), attribute.Int("turns", int(g.turn)))
+	g.gameSpan.End()
+	g.gameSpan = nil
+}
+
+// startStateSpan 进入新状态，座位上的机器人决策挂在该状态下
+func (g *Game) startStateSpan(name string) {
+	if g.gameSpan == nil {
+		return
+	}
+	var ctx context.Context
+	ctx, g.stateSpan = tracing.Tracer().Start(g.gameCtx, name, trace.WithAttributes(attribute.Int("turn", int(g.turn))))
+	for seat := range g.GetPlayerCount() {
+		tracing.BindSeat(g.GetPlayer(seat).Uid, ctx)
+	}
+}
+
+func (g *Game) endStateSpan() {
+	if g.stateSpan == nil {
+		return
+	}
+	g.stateSpan.End()
+	g.stateSpan = nil
+}
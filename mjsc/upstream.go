@@ -0,0 +1,64 @@
+package mjsc
+
+import (
+	"github.com/kevin-chtw/tw_common/gamebase/game"
+	"github.com/kevin-chtw/tw_common/gamebase/mahjong"
+)
+
+// 以下接口尚未合入 tw_common（见 UPSTREAM.md），按类型断言调用，
+// 当前依赖的版本不支持时退回原有行为，上游合入后不需要改动调用处
+
+// seedDealer 可以按种子洗牌的发牌器
+type seedDealer interface {
+	SetSeed(seed int64)
+}
+
+// bankerPlay 可以指定庄家的牌局
+type bankerPlay interface {
+	SetBanker(seat int32)
+}
+
+// countTable 知道比赛总局数的牌桌
+type countTable interface {
+	GetGameCount() int32
+}
+
+// idTable 知道比赛id、桌号的牌桌
+type idTable interface {
+	GetMatchID() int32
+	GetTableID() int32
+}
+
+// setDealerSeed 设置洗牌种子，发牌器不支持时返回 false
+func setDealerSeed(d *mahjong.Dealer, seed int64) bool {
+	sd, ok := any(d).(seedDealer)
+	if ok {
+		sd.SetSeed(seed)
+	}
+	return ok
+}
+
+// setBanker 指定庄家，不支持时返回 false，由基类定庄
+func setBanker(p *mahjong.Play, seat int32) bool {
+	bp, ok := any(p).(bankerPlay)
+	if ok {
+		bp.SetBanker(seat)
+	}
+	return ok
+}
+
+// tableGameCount 比赛总局数，不知道时为 0（不下发比赛总结算）
+func tableGameCount(t *game.Table) int32 {
+	if ct, ok := any(t).(countTable); ok {
+		return ct.GetGameCount()
+	}
+	return 0
+}
+
+// tableIDs 比赛id和桌号，不知道时为 0
+func tableIDs(t *game.Table) (matchID, tableID int32) {
+	if it, ok := any(t).(idTable); ok {
+		return it.GetMatchID(), it.GetTableID()
+	}
+	return 0, 0
+}
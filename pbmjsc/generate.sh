@@ -0,0 +1 @@
+protoc -I=. --go_out=. --go_opt=paths=source_relative *.proto
@@ -0,0 +1,949 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.7
+// 	protoc        (unknown)
+// source: mjsc.proto
+
+// 血战服务自有的消息，和 tw_proto 中的 SCReq/SCAck 一样通过 Any 收发
+
+package pbmjsc
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// 请求被拒绝，只下发给请求的玩家
+type SCErrorAck struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          int32                  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`                     // 拒绝原因
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`                  // 详细说明
+	Requestid     int32                  `protobuf:"varint,3,opt,name=requestid,proto3" json:"requestid,omitempty"`           // 被拒绝请求的请求ID，没有时为0
+	ReqType       string                 `protobuf:"bytes,4,opt,name=req_type,json=reqType,proto3" json:"req_type,omitempty"` // 被拒绝请求的消息全名
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SCErrorAck) Reset() {
+	*x = SCErrorAck{}
+	mi := &file_mjsc_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SCErrorAck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SCErrorAck) ProtoMessage() {}
+
+func (x *SCErrorAck) ProtoReflect() protoreflect.Message {
+	mi := &file_mjsc_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SCErrorAck.ProtoReflect.Descriptor instead.
+func (*SCErrorAck) Descriptor() ([]byte, []int) {
+	return file_mjsc_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SCErrorAck) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *SCErrorAck) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *SCErrorAck) GetRequestid() int32 {
+	if x != nil {
+		return x.Requestid
+	}
+	return 0
+}
+
+func (x *SCErrorAck) GetReqType() string {
+	if x != nil {
+		return x.ReqType
+	}
+	return ""
+}
+
+// 断线重连时补发的血战状态
+type SCSnapshotAck struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Phase           int32                  `protobuf:"varint,1,opt,name=phase,proto3" json:"phase,omitempty"`                                              // 牌局阶段：0发牌 1换三张 2定缺 3打牌
+	SwapSubmitted   []bool                 `protobuf:"varint,2,rep,packed,name=swap_submitted,json=swapSubmitted,proto3" json:"swap_submitted,omitempty"`  // 各座位是否已提交换三张
+	QueColors       []int32                `protobuf:"varint,3,rep,packed,name=que_colors,json=queColors,proto3" json:"que_colors,omitempty"`              // 各座位定缺花色，看不到时为未定义
+	QueSelected     []bool                 `protobuf:"varint,4,rep,packed,name=que_selected,json=queSelected,proto3" json:"que_selected,omitempty"`        // 各座位是否已定缺
+	OutSeats        []int32                `protobuf:"varint,5,rep,packed,name=out_seats,json=outSeats,proto3" json:"out_seats,omitempty"`                 // 已胡牌离场的座位
+	SwapTiles       []int32                `protobuf:"varint,6,rep,packed,name=swap_tiles,json=swapTiles,proto3" json:"swap_tiles,omitempty"`              // 自己已提交的换三张的牌
+	RequestRemainMs int64                  `protobuf:"varint,7,opt,name=request_remain_ms,json=requestRemainMs,proto3" json:"request_remain_ms,omitempty"` // 待响应请求的剩余时间（毫秒）
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *SCSnapshotAck) Reset() {
+	*x = SCSnapshotAck{}
+	mi := &file_mjsc_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SCSnapshotAck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SCSnapshotAck) ProtoMessage() {}
+
+func (x *SCSnapshotAck) ProtoReflect() protoreflect.Message {
+	mi := &file_mjsc_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SCSnapshotAck.ProtoReflect.Descriptor instead.
+func (*SCSnapshotAck) Descriptor() ([]byte, []int) {
+	return file_mjsc_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SCSnapshotAck) GetPhase() int32 {
+	if x != nil {
+		return x.Phase
+	}
+	return 0
+}
+
+func (x *SCSnapshotAck) GetSwapSubmitted() []bool {
+	if x != nil {
+		return x.SwapSubmitted
+	}
+	return nil
+}
+
+func (x *SCSnapshotAck) GetQueColors() []int32 {
+	if x != nil {
+		return x.QueColors
+	}
+	return nil
+}
+
+func (x *SCSnapshotAck) GetQueSelected() []bool {
+	if x != nil {
+		return x.QueSelected
+	}
+	return nil
+}
+
+func (x *SCSnapshotAck) GetOutSeats() []int32 {
+	if x != nil {
+		return x.OutSeats
+	}
+	return nil
+}
+
+func (x *SCSnapshotAck) GetSwapTiles() []int32 {
+	if x != nil {
+		return x.SwapTiles
+	}
+	return nil
+}
+
+func (x *SCSnapshotAck) GetRequestRemainMs() int64 {
+	if x != nil {
+		return x.RequestRemainMs
+	}
+	return 0
+}
+
+// 观战请求
+type SCWatchReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TargetUid     string                 `protobuf:"bytes,1,opt,name=target_uid,json=targetUid,proto3" json:"target_uid,omitempty"` // 观看该玩家所在的桌
+	Full          bool                   `protobuf:"varint,2,opt,name=full,proto3" json:"full,omitempty"`                           // 全信息观战，仅限管理员
+	Stop          bool                   `protobuf:"varint,3,opt,name=stop,proto3" json:"stop,omitempty"`                           // 停止观战
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SCWatchReq) Reset() {
+	*x = SCWatchReq{}
+	mi := &file_mjsc_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SCWatchReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SCWatchReq) ProtoMessage() {}
+
+func (x *SCWatchReq) ProtoReflect() protoreflect.Message {
+	mi := &file_mjsc_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SCWatchReq.ProtoReflect.Descriptor instead.
+func (*SCWatchReq) Descriptor() ([]byte, []int) {
+	return file_mjsc_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SCWatchReq) GetTargetUid() string {
+	if x != nil {
+		return x.TargetUid
+	}
+	return ""
+}
+
+func (x *SCWatchReq) GetFull() bool {
+	if x != nil {
+		return x.Full
+	}
+	return false
+}
+
+func (x *SCWatchReq) GetStop() bool {
+	if x != nil {
+		return x.Stop
+	}
+	return false
+}
+
+type SCWatchAck struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Full          bool                   `protobuf:"varint,1,opt,name=full,proto3" json:"full,omitempty"` // 实际的观战模式
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SCWatchAck) Reset() {
+	*x = SCWatchAck{}
+	mi := &file_mjsc_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SCWatchAck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SCWatchAck) ProtoMessage() {}
+
+func (x *SCWatchAck) ProtoReflect() protoreflect.Message {
+	mi := &file_mjsc_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SCWatchAck.ProtoReflect.Descriptor instead.
+func (*SCWatchAck) Descriptor() ([]byte, []int) {
+	return file_mjsc_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SCWatchAck) GetFull() bool {
+	if x != nil {
+		return x.Full
+	}
+	return false
+}
+
+// 等待碰杠胡时的预选
+type SCIntentReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AutoHu        bool                   `protobuf:"varint,1,opt,name=auto_hu,json=autoHu,proto3" json:"auto_hu,omitempty"`              // 能胡就胡
+	PassPon       bool                   `protobuf:"varint,2,opt,name=pass_pon,json=passPon,proto3" json:"pass_pon,omitempty"`           // 只能碰时一律过
+	PonTiles      []int32                `protobuf:"varint,3,rep,packed,name=pon_tiles,json=ponTiles,proto3" json:"pon_tiles,omitempty"` // 碰指定的牌，生效一次
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SCIntentReq) Reset() {
+	*x = SCIntentReq{}
+	mi := &file_mjsc_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SCIntentReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SCIntentReq) ProtoMessage() {}
+
+func (x *SCIntentReq) ProtoReflect() protoreflect.Message {
+	mi := &file_mjsc_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SCIntentReq.ProtoReflect.Descriptor instead.
+func (*SCIntentReq) Descriptor() ([]byte, []int) {
+	return file_mjsc_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SCIntentReq) GetAutoHu() bool {
+	if x != nil {
+		return x.AutoHu
+	}
+	return false
+}
+
+func (x *SCIntentReq) GetPassPon() bool {
+	if x != nil {
+		return x.PassPon
+	}
+	return false
+}
+
+func (x *SCIntentReq) GetPonTiles() []int32 {
+	if x != nil {
+		return x.PonTiles
+	}
+	return nil
+}
+
+type SCIntentAck struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AutoHu        bool                   `protobuf:"varint,1,opt,name=auto_hu,json=autoHu,proto3" json:"auto_hu,omitempty"`
+	PassPon       bool                   `protobuf:"varint,2,opt,name=pass_pon,json=passPon,proto3" json:"pass_pon,omitempty"`
+	PonTiles      []int32                `protobuf:"varint,3,rep,packed,name=pon_tiles,json=ponTiles,proto3" json:"pon_tiles,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SCIntentAck) Reset() {
+	*x = SCIntentAck{}
+	mi := &file_mjsc_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SCIntentAck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SCIntentAck) ProtoMessage() {}
+
+func (x *SCIntentAck) ProtoReflect() protoreflect.Message {
+	mi := &file_mjsc_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SCIntentAck.ProtoReflect.Descriptor instead.
+func (*SCIntentAck) Descriptor() ([]byte, []int) {
+	return file_mjsc_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *SCIntentAck) GetAutoHu() bool {
+	if x != nil {
+		return x.AutoHu
+	}
+	return false
+}
+
+func (x *SCIntentAck) GetPassPon() bool {
+	if x != nil {
+		return x.PassPon
+	}
+	return false
+}
+
+func (x *SCIntentAck) GetPonTiles() []int32 {
+	if x != nil {
+		return x.PonTiles
+	}
+	return nil
+}
+
+// 自动操作设置，同一场比赛内跨局保留
+type SCPreferenceReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AutoHu        bool                   `protobuf:"varint,1,opt,name=auto_hu,json=autoHu,proto3" json:"auto_hu,omitempty"`                // 能胡就胡（点炮、自摸、抢杠）
+	AutoPass      bool                   `protobuf:"varint,2,opt,name=auto_pass,json=autoPass,proto3" json:"auto_pass,omitempty"`          // 不能胡时碰、杠一律过
+	AutoDiscard   bool                   `protobuf:"varint,3,opt,name=auto_discard,json=autoDiscard,proto3" json:"auto_discard,omitempty"` // 听牌后摸到不能胡、不能杠的牌直接打出
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SCPreferenceReq) Reset() {
+	*x = SCPreferenceReq{}
+	mi := &file_mjsc_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SCPreferenceReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SCPreferenceReq) ProtoMessage() {}
+
+func (x *SCPreferenceReq) ProtoReflect() protoreflect.Message {
+	mi := &file_mjsc_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SCPreferenceReq.ProtoReflect.Descriptor instead.
+func (*SCPreferenceReq) Descriptor() ([]byte, []int) {
+	return file_mjsc_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *SCPreferenceReq) GetAutoHu() bool {
+	if x != nil {
+		return x.AutoHu
+	}
+	return false
+}
+
+func (x *SCPreferenceReq) GetAutoPass() bool {
+	if x != nil {
+		return x.AutoPass
+	}
+	return false
+}
+
+func (x *SCPreferenceReq) GetAutoDiscard() bool {
+	if x != nil {
+		return x.AutoDiscard
+	}
+	return false
+}
+
+type SCPreferenceAck struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AutoHu        bool                   `protobuf:"varint,1,opt,name=auto_hu,json=autoHu,proto3" json:"auto_hu,omitempty"`
+	AutoPass      bool                   `protobuf:"varint,2,opt,name=auto_pass,json=autoPass,proto3" json:"auto_pass,omitempty"`
+	AutoDiscard   bool                   `protobuf:"varint,3,opt,name=auto_discard,json=autoDiscard,proto3" json:"auto_discard,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SCPreferenceAck) Reset() {
+	*x = SCPreferenceAck{}
+	mi := &file_mjsc_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SCPreferenceAck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SCPreferenceAck) ProtoMessage() {}
+
+func (x *SCPreferenceAck) ProtoReflect() protoreflect.Message {
+	mi := &file_mjsc_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SCPreferenceAck.ProtoReflect.Descriptor instead.
+func (*SCPreferenceAck) Descriptor() ([]byte, []int) {
+	return file_mjsc_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *SCPreferenceAck) GetAutoHu() bool {
+	if x != nil {
+		return x.AutoHu
+	}
+	return false
+}
+
+func (x *SCPreferenceAck) GetAutoPass() bool {
+	if x != nil {
+		return x.AutoPass
+	}
+	return false
+}
+
+func (x *SCPreferenceAck) GetAutoDiscard() bool {
+	if x != nil {
+		return x.AutoDiscard
+	}
+	return false
+}
+
+// 复式赛一副牌墙轮换完毕
+type SCDuplicateResultAck struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Deal          int32                  `protobuf:"varint,1,opt,name=deal,proto3" json:"deal,omitempty"`            // 第几副牌墙
+	Scores        []int64                `protobuf:"varint,2,rep,packed,name=scores,proto3" json:"scores,omitempty"` // 各座位本副牌墙的复式分
+	Totals        []int64                `protobuf:"varint,3,rep,packed,name=totals,proto3" json:"totals,omitempty"` // 各座位累计复式分
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SCDuplicateResultAck) Reset() {
+	*x = SCDuplicateResultAck{}
+	mi := &file_mjsc_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SCDuplicateResultAck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SCDuplicateResultAck) ProtoMessage() {}
+
+func (x *SCDuplicateResultAck) ProtoReflect() protoreflect.Message {
+	mi := &file_mjsc_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SCDuplicateResultAck.ProtoReflect.Descriptor instead.
+func (*SCDuplicateResultAck) Descriptor() ([]byte, []int) {
+	return file_mjsc_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SCDuplicateResultAck) GetDeal() int32 {
+	if x != nil {
+		return x.Deal
+	}
+	return 0
+}
+
+func (x *SCDuplicateResultAck) GetScores() []int64 {
+	if x != nil {
+		return x.Scores
+	}
+	return nil
+}
+
+func (x *SCDuplicateResultAck) GetTotals() []int64 {
+	if x != nil {
+		return x.Totals
+	}
+	return nil
+}
+
+// 比赛中一个座位的累计数据
+type SCMatchPlayer struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Seat          int32                  `protobuf:"varint,1,opt,name=seat,proto3" json:"seat,omitempty"`                               // 座位号
+	Uid           string                 `protobuf:"bytes,2,opt,name=uid,proto3" json:"uid,omitempty"`                                  // 玩家uid
+	Rank          int32                  `protobuf:"varint,3,opt,name=rank,proto3" json:"rank,omitempty"`                               // 名次，同分同名次
+	TotalScore    int64                  `protobuf:"varint,4,opt,name=total_score,json=totalScore,proto3" json:"total_score,omitempty"` // 累计得分
+	HuCount       int32                  `protobuf:"varint,5,opt,name=hu_count,json=huCount,proto3" json:"hu_count,omitempty"`          // 胡牌次数
+	ZimoCount     int32                  `protobuf:"varint,6,opt,name=zimo_count,json=zimoCount,proto3" json:"zimo_count,omitempty"`    // 自摸次数
+	PaoCount      int32                  `protobuf:"varint,7,opt,name=pao_count,json=paoCount,proto3" json:"pao_count,omitempty"`       // 点炮次数
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SCMatchPlayer) Reset() {
+	*x = SCMatchPlayer{}
+	mi := &file_mjsc_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SCMatchPlayer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SCMatchPlayer) ProtoMessage() {}
+
+func (x *SCMatchPlayer) ProtoReflect() protoreflect.Message {
+	mi := &file_mjsc_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SCMatchPlayer.ProtoReflect.Descriptor instead.
+func (*SCMatchPlayer) Descriptor() ([]byte, []int) {
+	return file_mjsc_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *SCMatchPlayer) GetSeat() int32 {
+	if x != nil {
+		return x.Seat
+	}
+	return 0
+}
+
+func (x *SCMatchPlayer) GetUid() string {
+	if x != nil {
+		return x.Uid
+	}
+	return ""
+}
+
+func (x *SCMatchPlayer) GetRank() int32 {
+	if x != nil {
+		return x.Rank
+	}
+	return 0
+}
+
+func (x *SCMatchPlayer) GetTotalScore() int64 {
+	if x != nil {
+		return x.TotalScore
+	}
+	return 0
+}
+
+func (x *SCMatchPlayer) GetHuCount() int32 {
+	if x != nil {
+		return x.HuCount
+	}
+	return 0
+}
+
+func (x *SCMatchPlayer) GetZimoCount() int32 {
+	if x != nil {
+		return x.ZimoCount
+	}
+	return 0
+}
+
+func (x *SCMatchPlayer) GetPaoCount() int32 {
+	if x != nil {
+		return x.PaoCount
+	}
+	return 0
+}
+
+// 比赛中一局的记录
+type SCMatchGame struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Index         int32                  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`                           // 第几局
+	Banker        int32                  `protobuf:"varint,2,opt,name=banker,proto3" json:"banker,omitempty"`                         // 庄家
+	PaoSeat       int32                  `protobuf:"varint,3,opt,name=pao_seat,json=paoSeat,proto3" json:"pao_seat,omitempty"`        // 第一个点炮的座位，没有时为-1
+	Scores        []int64                `protobuf:"varint,4,rep,packed,name=scores,proto3" json:"scores,omitempty"`                  // 各座位本局输赢
+	HuSeats       []int32                `protobuf:"varint,5,rep,packed,name=hu_seats,json=huSeats,proto3" json:"hu_seats,omitempty"` // 胡牌的座位，按胡牌顺序
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SCMatchGame) Reset() {
+	*x = SCMatchGame{}
+	mi := &file_mjsc_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SCMatchGame) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SCMatchGame) ProtoMessage() {}
+
+func (x *SCMatchGame) ProtoReflect() protoreflect.Message {
+	mi := &file_mjsc_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SCMatchGame.ProtoReflect.Descriptor instead.
+func (*SCMatchGame) Descriptor() ([]byte, []int) {
+	return file_mjsc_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SCMatchGame) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *SCMatchGame) GetBanker() int32 {
+	if x != nil {
+		return x.Banker
+	}
+	return 0
+}
+
+func (x *SCMatchGame) GetPaoSeat() int32 {
+	if x != nil {
+		return x.PaoSeat
+	}
+	return 0
+}
+
+func (x *SCMatchGame) GetScores() []int64 {
+	if x != nil {
+		return x.Scores
+	}
+	return nil
+}
+
+func (x *SCMatchGame) GetHuSeats() []int32 {
+	if x != nil {
+		return x.HuSeats
+	}
+	return nil
+}
+
+// 比赛全部局数结束
+type SCMatchResultAck struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Players       []*SCMatchPlayer       `protobuf:"bytes,1,rep,name=players,proto3" json:"players,omitempty"` // 按名次排序
+	Games         []*SCMatchGame         `protobuf:"bytes,2,rep,name=games,proto3" json:"games,omitempty"`     // 每局记录
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SCMatchResultAck) Reset() {
+	*x = SCMatchResultAck{}
+	mi := &file_mjsc_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SCMatchResultAck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SCMatchResultAck) ProtoMessage() {}
+
+func (x *SCMatchResultAck) ProtoReflect() protoreflect.Message {
+	mi := &file_mjsc_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SCMatchResultAck.ProtoReflect.Descriptor instead.
+func (*SCMatchResultAck) Descriptor() ([]byte, []int) {
+	return file_mjsc_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *SCMatchResultAck) GetPlayers() []*SCMatchPlayer {
+	if x != nil {
+		return x.Players
+	}
+	return nil
+}
+
+func (x *SCMatchResultAck) GetGames() []*SCMatchGame {
+	if x != nil {
+		return x.Games
+	}
+	return nil
+}
+
+var File_mjsc_proto protoreflect.FileDescriptor
+
+const file_mjsc_proto_rawDesc = "" +
+	"\n" +
+	"\n" +
+	"mjsc.proto\x12\x06pbmjsc\"q\n" +
+	"\n" +
+	"SCErrorAck\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\x12\x1c\n" +
+	"\trequestid\x18\x03 \x01(\x05R\trequestid\x12\x19\n" +
+	"\breq_type\x18\x04 \x01(\tR\areqType\"\xf6\x01\n" +
+	"\rSCSnapshotAck\x12\x14\n" +
+	"\x05phase\x18\x01 \x01(\x05R\x05phase\x12%\n" +
+	"\x0eswap_submitted\x18\x02 \x03(\bR\rswapSubmitted\x12\x1d\n" +
+	"\n" +
+	"que_colors\x18\x03 \x03(\x05R\tqueColors\x12!\n" +
+	"\fque_selected\x18\x04 \x03(\bR\vqueSelected\x12\x1b\n" +
+	"\tout_seats\x18\x05 \x03(\x05R\boutSeats\x12\x1d\n" +
+	"\n" +
+	"swap_tiles\x18\x06 \x03(\x05R\tswapTiles\x12*\n" +
+	"\x11request_remain_ms\x18\a \x01(\x03R\x0frequestRemainMs\"S\n" +
+	"\n" +
+	"SCWatchReq\x12\x1d\n" +
+	"\n" +
+	"target_uid\x18\x01 \x01(\tR\ttargetUid\x12\x12\n" +
+	"\x04full\x18\x02 \x01(\bR\x04full\x12\x12\n" +
+	"\x04stop\x18\x03 \x01(\bR\x04stop\" \n" +
+	"\n" +
+	"SCWatchAck\x12\x12\n" +
+	"\x04full\x18\x01 \x01(\bR\x04full\"^\n" +
+	"\vSCIntentReq\x12\x17\n" +
+	"\aauto_hu\x18\x01 \x01(\bR\x06autoHu\x12\x19\n" +
+	"\bpass_pon\x18\x02 \x01(\bR\apassPon\x12\x1b\n" +
+	"\tpon_tiles\x18\x03 \x03(\x05R\bponTiles\"^\n" +
+	"\vSCIntentAck\x12\x17\n" +
+	"\aauto_hu\x18\x01 \x01(\bR\x06autoHu\x12\x19\n" +
+	"\bpass_pon\x18\x02 \x01(\bR\apassPon\x12\x1b\n" +
+	"\tpon_tiles\x18\x03 \x03(\x05R\bponTiles\"j\n" +
+	"\x0fSCPreferenceReq\x12\x17\n" +
+	"\aauto_hu\x18\x01 \x01(\bR\x06autoHu\x12\x1b\n" +
+	"\tauto_pass\x18\x02 \x01(\bR\bautoPass\x12!\n" +
+	"\fauto_discard\x18\x03 \x01(\bR\vautoDiscard\"j\n" +
+	"\x0fSCPreferenceAck\x12\x17\n" +
+	"\aauto_hu\x18\x01 \x01(\bR\x06autoHu\x12\x1b\n" +
+	"\tauto_pass\x18\x02 \x01(\bR\bautoPass\x12!\n" +
+	"\fauto_discard\x18\x03 \x01(\bR\vautoDiscard\"Z\n" +
+	"\x14SCDuplicateResultAck\x12\x12\n" +
+	"\x04deal\x18\x01 \x01(\x05R\x04deal\x12\x16\n" +
+	"\x06scores\x18\x02 \x03(\x03R\x06scores\x12\x16\n" +
+	"\x06totals\x18\x03 \x03(\x03R\x06totals\"\xc1\x01\n" +
+	"\rSCMatchPlayer\x12\x12\n" +
+	"\x04seat\x18\x01 \x01(\x05R\x04seat\x12\x10\n" +
+	"\x03uid\x18\x02 \x01(\tR\x03uid\x12\x12\n" +
+	"\x04rank\x18\x03 \x01(\x05R\x04rank\x12\x1f\n" +
+	"\vtotal_score\x18\x04 \x01(\x03R\n" +
+	"totalScore\x12\x19\n" +
+	"\bhu_count\x18\x05 \x01(\x05R\ahuCount\x12\x1d\n" +
+	"\n" +
+	"zimo_count\x18\x06 \x01(\x05R\tzimoCount\x12\x1b\n" +
+	"\tpao_count\x18\a \x01(\x05R\bpaoCount\"\x89\x01\n" +
+	"\vSCMatchGame\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x05R\x05index\x12\x16\n" +
+	"\x06banker\x18\x02 \x01(\x05R\x06banker\x12\x19\n" +
+	"\bpao_seat\x18\x03 \x01(\x05R\apaoSeat\x12\x16\n" +
+	"\x06scores\x18\x04 \x03(\x03R\x06scores\x12\x19\n" +
+	"\bhu_seats\x18\x05 \x03(\x05R\ahuSeats\"n\n" +
+	"\x10SCMatchResultAck\x12/\n" +
+	"\aplayers\x18\x01 \x03(\v2\x15.pbmjsc.SCMatchPlayerR\aplayers\x12)\n" +
+	"\x05games\x18\x02 \x03(\v2\x13.pbmjsc.SCMatchGameR\x05gamesB*Z(github.com/kevin-chtw/tw_mjsc_svr/pbmjscb\x06proto3"
+
+var (
+	file_mjsc_proto_rawDescOnce sync.Once
+	file_mjsc_proto_rawDescData []byte
+)
+
+func file_mjsc_proto_rawDescGZIP() []byte {
+	file_mjsc_proto_rawDescOnce.Do(func() {
+		file_mjsc_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_mjsc_proto_rawDesc), len(file_mjsc_proto_rawDesc)))
+	})
+	return file_mjsc_proto_rawDescData
+}
+
+var file_mjsc_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_mjsc_proto_goTypes = []any{
+	(*SCErrorAck)(nil),           // 0: pbmjsc.SCErrorAck
+	(*SCSnapshotAck)(nil),        // 1: pbmjsc.SCSnapshotAck
+	(*SCWatchReq)(nil),           // 2: pbmjsc.SCWatchReq
+	(*SCWatchAck)(nil),           // 3: pbmjsc.SCWatchAck
+	(*SCIntentReq)(nil),          // 4: pbmjsc.SCIntentReq
+	(*SCIntentAck)(nil),          // 5: pbmjsc.SCIntentAck
+	(*SCPreferenceReq)(nil),      // 6: pbmjsc.SCPreferenceReq
+	(*SCPreferenceAck)(nil),      // 7: pbmjsc.SCPreferenceAck
+	(*SCDuplicateResultAck)(nil), // 8: pbmjsc.SCDuplicateResultAck
+	(*SCMatchPlayer)(nil),        // 9: pbmjsc.SCMatchPlayer
+	(*SCMatchGame)(nil),          // 10: pbmjsc.SCMatchGame
+	(*SCMatchResultAck)(nil),     // 11: pbmjsc.SCMatchResultAck
+}
+var file_mjsc_proto_depIdxs = []int32{
+	9,  // 0: pbmjsc.SCMatchResultAck.players:type_name -> pbmjsc.SCMatchPlayer
+	10, // 1: pbmjsc.SCMatchResultAck.games:type_name -> pbmjsc.SCMatchGame
+	2,  // [2:2] is the sub-list for method output_type
+	2,  // [2:2] is the sub-list for method input_type
+	2,  // [2:2] is the sub-list for extension type_name
+	2,  // [2:2] is the sub-list for extension extendee
+	0,  // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_mjsc_proto_init() }
+func file_mjsc_proto_init() {
+	if File_mjsc_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_mjsc_proto_rawDesc), len(file_mjsc_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_mjsc_proto_goTypes,
+		DependencyIndexes: file_mjsc_proto_depIdxs,
+		MessageInfos:      file_mjsc_proto_msgTypes,
+	}.Build()
+	File_mjsc_proto = out.File
+	file_mjsc_proto_goTypes = nil
+	file_mjsc_proto_depIdxs = nil
+}
@@ -10,6 +10,9 @@ import numpy as np
 import random
 from collections import deque
 import sys
+import os
+import glob
+import gzip
 import logging
 
 # 配置日志
@@ -32,6 +35,10 @@ except ImportError:
     HAS_TORCH = False
     logger.warning("⚠️  PyTorch not available, using random policy")
 
+# 观察向量维度（需与Go侧 ai.FeatureDim 一致）
+FEATURE_DIMS = {1: 4356, 2: 4806}
+FEATURE_VERSION = int(os.environ.get('MJ_FEATURE_VERSION', '1'))
+
 class DQN(nn.Module if HAS_TORCH else object):
     """Dueling DQN 网络 - 适合麻将AI
     
@@ -41,7 +48,7 @@ class DQN(nn.Module if HAS_TORCH else object):
     3. LayerNorm：稳定训练过程
     4. 合理的网络深度：足够表达复杂策略，但不会过拟合
     """
-    def __init__(self, input_dim=4356, hidden_dim=512, output_dim=137):
+    def __init__(self, input_dim=FEATURE_DIMS[FEATURE_VERSION], hidden_dim=512, output_dim=137):
         if HAS_TORCH:
             super().__init__()
             
@@ -513,7 +520,30 @@ def serve(port=50051):
         ai_service.save_model()
         server.shutdown()
 
+def load_dataset(dataset_dir):
+    """读取Go侧 FileSink 写出的数据集（episodes-*.jsonl.gz）"""
+    for shard in sorted(glob.glob(os.path.join(dataset_dir, 'episodes-*.jsonl.gz'))):
+        with gzip.open(shard, 'rt', encoding='utf-8') as f:
+            for line in f:
+                line = line.strip()
+                if line:
+                    yield json.loads(line)
+
+def train_offline(dataset_dir, epochs=1):
+    """离线训练：重复读取本地数据集，不依赖在线对局"""
+    for epoch in range(epochs):
+        count = 0
+        for episode in load_dataset(dataset_dir):
+            ai_service.report_episode(episode)
+            count += 1
+        logger.info(f"📚 Offline epoch {epoch + 1}/{epochs}: {count} episodes")
+    ai_service.save_model()
+
 if __name__ == '__main__':
+    if len(sys.argv) > 2 and sys.argv[1] == 'offline':
+        epochs = int(sys.argv[3]) if len(sys.argv) > 3 else 1
+        train_offline(sys.argv[2], epochs)
+        sys.exit(0)
     port = int(sys.argv[1]) if len(sys.argv) > 1 else 50051
     serve(port)
 
@@ -0,0 +1,76 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	ExporterNone   = ""       // 不采集
+	ExporterStdout = "stdout" // 输出 JSON 到 stdout 或指定文件
+)
+
+// 未 Init 时使用全局默认的空实现，span 不产生开销
+var (
+	tracer = otel.Tracer("github.com/kevin-chtw/tw_mjsc_svr")
+	seats  sync.Map // 玩家uid -> 所在桌当前状态的 context，机器人决策挂在其下
+)
+
+// Init 按导出方式初始化 TracerProvider，返回退出时调用的关闭函数
+func Init(exporter string, w io.Writer) (func(context.Context) error, error) {
+	var opt sdktrace.TracerProviderOption
+	switch exporter {
+	case ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		exp, err := stdouttrace.New(stdouttrace.WithWriter(w))
+		if err != nil {
+			return nil, err
+		}
+		opt = sdktrace.WithBatcher(exp)
+	default:
+		return nil, fmt.Errorf("unknown trace exporter: %s", exporter)
+	}
+
+	tp := sdktrace.NewTracerProvider(opt)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return tp.Shutdown, nil
+}
+
+// Tracer 本服务的 tracer
+func Tracer() trace.Tracer {
+	return tracer
+}
+
+// Inject 将 trace context 写入 HTTP 请求头，传给 AI 服务
+func Inject(ctx context.Context, header http.Header) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+}
+
+// BindSeat 登记玩家当前所在状态的 context
+func BindSeat(uid string, ctx context.Context) {
+	seats.Store(uid, ctx)
+}
+
+// UnbindSeat 牌局结束后解除登记，避免机器人的决策挂到上一桌的状态下
+func UnbindSeat(uid string) {
+	seats.Delete(uid)
+}
+
+// SeatContext 玩家所在状态的 context，未登记时返回 context.Background()
+func SeatContext(uid string) context.Context {
+	if v, ok := seats.Load(uid); ok {
+		return v.(context.Context)
+	}
+	return context.Background()
+}
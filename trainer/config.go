@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/kevin-chtw/tw_mjsc_svr/ai"
+)
+
+// Config 训练运行配置，优先级：默认值 < 配置文件 < 命令行参数
+type Config struct {
+	Tables         int              `json:"tables"`          // 桌数
+	GamesPerTable  int32            `json:"games_per_table"` // 每桌局数
+	MatchType      string           `json:"match_type"`      // 比赛类型
+	Rules          map[string]int32 `json:"rules"`           // 规则（同 fdRules 的键）
+	Seed           int64            `json:"seed"`            // 牌墙种子，第i桌使用 seed+i，0表示不固定
+	Strategies     []string         `json:"strategies"`      // 每个座位的策略
+	AIAddr         string           `json:"ai_addr"`         // Python AI 服务地址
+	OutputDir      string           `json:"output_dir"`      // 输出目录（manifest、summary、数据集）
+	ShardSize      int              `json:"shard_size"`      // 数据集每个分片的局数，0表示不写本地数据集
+	RewardConfig   string           `json:"reward_config"`   // 奖励配置文件
+	FeatureVersion int              `json:"feature_version"` // 观察向量版本
+	StartDelayMs   int              `json:"start_delay_ms"`  // 启动后等待多久开桌
+	Debug          bool             `json:"debug"`           // 机器人每步校验状态
+	BotLogSample   int              `json:"bot_log_sample"`  // 机器人详细日志每 N 条输出一条，0 不输出
+	Trace          bool             `json:"trace"`           // 记录每局、每个状态和决策的 span 到 trace.jsonl
+}
+
+func defaultConfig() *Config {
+	return &Config{
+		Tables:         5,
+		GamesPerTable:  20000,
+		MatchType:      "trainer",
+		Rules:          map[string]int32{},
+		Strategies:     []string{ai.StrategyAI, ai.StrategyAI, ai.StrategyAI, ai.StrategyAI},
+		AIAddr:         "localhost:50051",
+		OutputDir:      "runs",
+		FeatureVersion: ai.FeatureV1,
+		StartDelayMs:   1000,
+		BotLogSample:   100,
+	}
+}
+
+func parseConfig(args []string) (*Config, error) {
+	cfg := defaultConfig()
+	fs := flag.NewFlagSet("trainer", flag.ContinueOnError)
+	configPath := fs.String("config", "", "json config file")
+	tables := fs.Int("tables", cfg.Tables, "number of tables")
+	games := fs.Int("games", int(cfg.GamesPerTable), "games per table")
+	matchType := fs.String("match-type", cfg.MatchType, "match type")
+	rules := fs.String("rules", "", "rules, e.g. huansz=1,maxmulti=8")
+	seed := fs.Int64("seed", cfg.Seed, "base random seed")
+	strategies := fs.String("strategies", strings.Join(cfg.Strategies, ","), "strategy per seat, e.g. ai,ai,rule,rule")
+	aiAddr := fs.String("ai", cfg.AIAddr, "python AI service address")
+	outputDir := fs.String("out", cfg.OutputDir, "output directory")
+	shardSize := fs.Int("shard", cfg.ShardSize, "episodes per dataset shard, 0 disables local dataset")
+	reward := fs.String("reward", cfg.RewardConfig, "reward config json file")
+	feature := fs.Int("feature", cfg.FeatureVersion, "observation feature version")
+	delay := fs.Int("delay-ms", cfg.StartDelayMs, "delay before creating tables")
+	debug := fs.Bool("debug", cfg.Debug, "check bot state against server after each ack")
+	logSample := fs.Int("log-sample", cfg.BotLogSample, "log 1 of every N verbose bot messages, 0 disables")
+	trace := fs.Bool("trace", cfg.Trace, "write game, state and decision spans to trace.jsonl")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if *configPath != "" {
+		data, err := os.ReadFile(*configPath)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("parse config %s: %w", *configPath, err)
+		}
+	}
+
+	var err error
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "tables":
+			cfg.Tables = *tables
+		case "games":
+			cfg.GamesPerTable = int32(*games)
+		case "match-type":
+			cfg.MatchType = *matchType
+		case "rules":
+			cfg.Rules, err = parseRules(*rules)
+		case "seed":
+			cfg.Seed = *seed
+		case "strategies":
+			cfg.Strategies = strings.Split(*strategies, ",")
+		case "ai":
+			cfg.AIAddr = *aiAddr
+		case "out":
+			cfg.OutputDir = *outputDir
+		case "shard":
+			cfg.ShardSize = *shardSize
+		case "reward":
+			cfg.RewardConfig = *reward
+		case "feature":
+			cfg.FeatureVersion = *feature
+		case "delay-ms":
+			cfg.StartDelayMs = *delay
+		case "debug":
+			cfg.Debug = *debug
+		case "log-sample":
+			cfg.BotLogSample = *logSample
+		case "trace":
+			cfg.Trace = *trace
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cfg, cfg.validate()
+}
+
+func (c *Config) validate() error {
+	if c.Tables <= 0 || c.GamesPerTable <= 0 {
+		return fmt.Errorf("tables and games must be positive")
+	}
+	// 种子以 int32 规则值下发到牌桌
+	if c.Seed < 0 || c.Seed+int64(c.Tables-1) > math.MaxInt32 {
+		return fmt.Errorf("seed %d out of range for %d tables", c.Seed, c.Tables)
+	}
+	if c.BotLogSample < 0 {
+		return fmt.Errorf("invalid bot_log_sample %d", c.BotLogSample)
+	}
+	if len(c.Strategies) != playerCount {
+		return fmt.Errorf("need %d strategies, got %d", playerCount, len(c.Strategies))
+	}
+	for _, name := range c.Strategies {
+		if _, err := ai.NewStrategy(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseRules 解析 "key=value,key=value" 格式的规则
+func parseRules(s string) (map[string]int32, error) {
+	rules := make(map[string]int32)
+	for _, kv := range strings.Split(s, ",") {
+		if kv == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid rule: %s", kv)
+		}
+		n, err := strconv.ParseInt(v, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rule value %s: %w", kv, err)
+		}
+		rules[k] = int32(n)
+	}
+	return rules, nil
+}
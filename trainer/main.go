@@ -2,6 +2,10 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"maps"
+	"os"
+	"path/filepath"
 	"strconv"
 	"time"
 
@@ -9,7 +13,9 @@ import (
 	"github.com/kevin-chtw/tw_common/utils"
 	"github.com/kevin-chtw/tw_mjsc_svr/ai"
 	"github.com/kevin-chtw/tw_mjsc_svr/bot"
+	"github.com/kevin-chtw/tw_mjsc_svr/conf"
 	"github.com/kevin-chtw/tw_mjsc_svr/mjsc"
+	"github.com/kevin-chtw/tw_mjsc_svr/tracing"
 	"github.com/kevin-chtw/tw_proto/sproto"
 	"github.com/sirupsen/logrus"
 	pitaya "github.com/topfreegames/pitaya/v3/pkg"
@@ -18,51 +24,157 @@ import (
 	"github.com/topfreegames/pitaya/v3/pkg/serialize"
 )
 
+const playerCount = 4
+
 var app pitaya.Pitaya
 
 func main() {
-	// 开启训练模式
-	ai.SetTrainingMode(true)
+	pitaya.SetLogger(utils.Logger(logrus.WarnLevel))
+
+	cfg, err := parseConfig(os.Args[1:])
+	if err != nil {
+		logger.Log.Fatalf("Invalid trainer config: %v", err)
+	}
+	runDir := filepath.Join(cfg.OutputDir, time.Now().Format("20060102-150405"))
+	if err := writeJSON(runDir, "manifest.json", newManifest(cfg)); err != nil {
+		logger.Log.Fatalf("Failed to write manifest: %v", err)
+	}
+
+	// 所有桌均为训练模式
+	runCfg := conf.Default()
+	runCfg.AIAddr = cfg.AIAddr
+	runCfg.Default = conf.Profile{Mode: conf.ModeTraining, Debug: cfg.Debug, BotLogSample: cfg.BotLogSample}
+	runCfg.Tables[cfg.MatchType] = runCfg.Default
+	conf.Set(runCfg)
+	ai.SetFeatureVersion(cfg.FeatureVersion)
+
+	if cfg.RewardConfig != "" {
+		rewardCfg, err := ai.LoadRewardConfig(cfg.RewardConfig)
+		if err != nil {
+			logger.Log.Fatalf("Failed to load reward config: %v", err)
+		}
+		fn, err := ai.NewRewardFunc(rewardCfg)
+		if err != nil {
+			logger.Log.Fatalf("Invalid reward config: %v", err)
+		}
+		ai.SetRewardFunc(fn)
+	}
 
 	// 初始化 Python AI 服务客户端
-	if err := ai.InitHTTPAIClient("localhost:50051"); err != nil {
+	if err := ai.InitHTTPAIClient(cfg.AIAddr); err != nil {
 		logger.Log.Fatalf("Failed to init AI client: %v", err)
 	}
 	defer ai.GetHTTPAIClient().Close()
 
-	serverType := utils.MJSC + "_trainer"
-	pitaya.SetLogger(utils.Logger(logrus.WarnLevel))
+	// 本地数据集与在线训练同时输出
+	if cfg.ShardSize > 0 {
+		sink, err := ai.NewFileSink(filepath.Join(runDir, "dataset"), cfg.ShardSize, datasetMeta(cfg))
+		if err != nil {
+			logger.Log.Fatalf("Failed to create dataset: %v", err)
+		}
+		ai.SetEpisodeSink(ai.MultiSink{ai.GetHTTPAIClient(), sink})
+		defer sink.Close()
+	}
+
+	if cfg.Trace {
+		shutdown, err := initTrace(runDir)
+		if err != nil {
+			logger.Log.Fatalf("Failed to init tracing: %v", err)
+		}
+		defer shutdown()
+	}
+
+	rec := newRecorder(cfg)
+	bot.SetResultHook(rec.onResult)
 
+	serverType := utils.MJSC + "_trainer"
 	config := config.NewDefaultPitayaConfig()
 	config.SerializerType = uint16(serialize.PROTOBUF)
 	config.Handler.Messages.Compression = false
 	builder := pitaya.NewDefaultBuilder(false, serverType, pitaya.Cluster, map[string]string{}, *config)
 	app = builder.Build()
 
-	defer app.Shutdown()
-
 	logger.Log.Infof("Pitaya server of type %s started", serverType)
 	game.Init(app, mjsc.NewGame, bot.NewPlayer)
-	go train()
+	go train(cfg, rec)
 	app.Start()
+
+	if err := writeJSON(runDir, "summary.json", rec.finish()); err != nil {
+		logger.Log.Errorf("Failed to write summary: %v", err)
+	}
 }
 
-func train() {
-	time.Sleep(time.Second)
-	for i := range 5 {
+func train(cfg *Config, rec *recorder) {
+	time.Sleep(time.Duration(cfg.StartDelayMs) * time.Millisecond)
+	for i := range cfg.Tables {
+		rules := maps.Clone(cfg.Rules)
+		if cfg.Seed != 0 {
+			rules["seed"] = int32(tableSeed(cfg, i))
+		}
+		gameConfig, err := json.Marshal(rules)
+		if err != nil {
+			logger.Log.Fatalf("Failed to marshal rules: %v", err)
+		}
 		table := game.GetTableManager().LoadOrStore(1, int32(i+1))
 		table.HandleAddTable(context.Background(), &sproto.AddTableReq{
 			ScoreBase:   1,
-			GameCount:   20000,
-			PlayerCount: 4,
-			MatchType:   "trainer",
+			GameCount:   cfg.GamesPerTable,
+			PlayerCount: playerCount,
+			MatchType:   cfg.MatchType,
+			GameConfig:  string(gameConfig),
 		})
-		for j := range 4 {
+		for j := range playerCount {
+			uid := strconv.Itoa(i*playerCount + j + 1)
+			bot.SetStrategy(uid, cfg.Strategies[j])
+			if cfg.Seed != 0 {
+				bot.SetSeed(uid, tableSeed(cfg, i))
+			}
+			rec.addBot(uid, i, int32(j), cfg.Strategies[j])
 			table.HandleAddPlayer(context.Background(), &sproto.AddPlayerReq{
-				Playerid: strconv.Itoa(i*4 + j + 1),
+				Playerid: uid,
 				Bot:      true,
 				Seat:     int32(j),
 			})
 		}
 	}
+
+	<-rec.done
+	logger.Log.Warnf("all %d tables finished", cfg.Tables)
+	app.Shutdown()
+}
+
+// initTrace span 以 JSON 写入运行目录下的 trace.jsonl
+func initTrace(runDir string) (func(), error) {
+	f, err := os.Create(filepath.Join(runDir, "trace.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	shutdownTrace, err := tracing.Init(tracing.ExporterStdout, f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() {
+		if err := shutdownTrace(context.Background()); err != nil {
+			logger.Log.Errorf("Failed to flush trace: %v", err)
+		}
+		f.Close()
+	}, nil
+}
+
+func datasetMeta(cfg *Config) *ai.DatasetMeta {
+	meta := &ai.DatasetMeta{
+		Name:  filepath.Base(cfg.OutputDir),
+		Rules: cfg.Rules,
+		Seats: make(map[int]string),
+	}
+	if cfg.Seed != 0 {
+		for i := range cfg.Tables {
+			meta.Seeds = append(meta.Seeds, tableSeed(cfg, i))
+		}
+	}
+	for seat, name := range cfg.Strategies {
+		meta.Seats[seat] = name
+	}
+	return meta
 }
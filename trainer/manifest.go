@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/kevin-chtw/tw_mjsc_svr/bot"
+)
+
+// Manifest 运行开始时写入，记录本次实验的完整配置
+type Manifest struct {
+	StartTime time.Time `json:"start_time"`
+	Args      []string  `json:"args"`
+	GoVersion string    `json:"go_version"`
+	Seeds     []int64   `json:"seeds,omitempty"` // 每桌种子，未设置种子时为空
+	Config    *Config   `json:"config"`
+}
+
+// StrategyStats 按策略汇总的结果
+type StrategyStats struct {
+	Games      int     `json:"games"`
+	TotalScore int64   `json:"total_score"`
+	MeanScore  float64 `json:"mean_score"`
+	Wins       int     `json:"wins"` // 得分为正的局数
+}
+
+// Summary 运行结束时写入
+type Summary struct {
+	StartTime  time.Time                 `json:"start_time"`
+	EndTime    time.Time                 `json:"end_time"`
+	Duration   string                    `json:"duration"`
+	Games      int                       `json:"games"`
+	Strategies map[string]*StrategyStats `json:"strategies"`
+}
+
+type botInfo struct {
+	table    int
+	seat     int32
+	strategy string
+}
+
+// recorder 收集各机器人上报的结算结果
+type recorder struct {
+	mu        sync.Mutex
+	cfg       *Config
+	start     time.Time
+	bots      map[string]*botInfo
+	tableDone []int32
+	summary   *Summary
+	done      chan struct{}
+}
+
+func newRecorder(cfg *Config) *recorder {
+	return &recorder{
+		cfg:       cfg,
+		start:     time.Now(),
+		bots:      make(map[string]*botInfo),
+		tableDone: make([]int32, cfg.Tables),
+		summary: &Summary{
+			Strategies: make(map[string]*StrategyStats),
+		},
+		done: make(chan struct{}),
+	}
+}
+
+func (r *recorder) addBot(uid string, table int, seat int32, strategy string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.bots[uid] = &botInfo{table: table, seat: seat, strategy: strategy}
+}
+
+// onResult 每个机器人都会收到结算，只统计自己座位的得分，由0号座位计局数
+func (r *recorder) onResult(res *bot.GameResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	info, ok := r.bots[res.Uid]
+	if !ok {
+		return
+	}
+	for _, result := range res.Ack.PlayerResults {
+		if result.Seat != res.Seat {
+			continue
+		}
+		stats, ok := r.summary.Strategies[info.strategy]
+		if !ok {
+			stats = &StrategyStats{}
+			r.summary.Strategies[info.strategy] = stats
+		}
+		stats.Games++
+		stats.TotalScore += result.WinScore
+		if result.WinScore > 0 {
+			stats.Wins++
+		}
+	}
+	if info.seat != 0 {
+		return
+	}
+	r.summary.Games++
+	r.tableDone[info.table]++
+	for _, n := range r.tableDone {
+		if n < r.cfg.GamesPerTable {
+			return
+		}
+	}
+	select {
+	case <-r.done:
+	default:
+		close(r.done)
+	}
+}
+
+func (r *recorder) finish() *Summary {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	end := time.Now()
+	r.summary.StartTime = r.start
+	r.summary.EndTime = end
+	r.summary.Duration = end.Sub(r.start).String()
+	for _, stats := range r.summary.Strategies {
+		if stats.Games > 0 {
+			stats.MeanScore = float64(stats.TotalScore) / float64(stats.Games)
+		}
+	}
+	return r.summary
+}
+
+func newManifest(cfg *Config) *Manifest {
+	m := &Manifest{
+		StartTime: time.Now(),
+		Args:      os.Args,
+		GoVersion: runtime.Version(),
+		Config:    cfg,
+	}
+	if cfg.Seed != 0 {
+		for i := range cfg.Tables {
+			m.Seeds = append(m.Seeds, tableSeed(cfg, i))
+		}
+	}
+	return m
+}
+
+// tableSeed 第 table 桌的牌墙种子，未设置种子时为0（随机牌墙）
+func tableSeed(cfg *Config, table int) int64 {
+	if cfg.Seed == 0 {
+		return 0
+	}
+	return cfg.Seed + int64(table)
+}
+
+func writeJSON(dir, name string, v any) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, name), data, 0o644)
+}